@@ -0,0 +1,535 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/hugoconfig"
+	"hugo-publisher/pkg/notify"
+	"hugo-publisher/pkg/posts"
+)
+
+// cliCommand runs a subcommand and returns the value printed as JSON
+type cliCommand func(a *App, args []string) (interface{}, error)
+
+// cliCommands are the subcommands run headless, without opening the window.
+// They call the same App methods as the GUI.
+var cliCommands = map[string]cliCommand{
+	"new":      newCommand,
+	"list":     listCommand,
+	"search":   searchCommand,
+	"edit":     editCommand,
+	"delete":   deleteCommand,
+	"images":   imagesCommand,
+	"indexnow": indexNowCommand,
+	"help":     helpCommand,
+}
+
+// cliUsage lists the subcommands for `hugo-publisher help`
+var cliUsage = []string{
+	"hugo-publisher new [-dir <posts>] -title <title> [post flags]",
+	"hugo-publisher list [-dir <posts>] [-search <text>] [filter flags] [-sort lastmod|date|title|weight|wordCount] [-order asc|desc] [-page <n> -size <n>]",
+	"hugo-publisher search [-dir <posts>] [filter flags] [-page <n> -size <n>] <words>...",
+	"hugo-publisher edit [-dir <posts>] -id <id> [post flags]",
+	"hugo-publisher delete [-dir <posts>] -id <id> [-images <image dir>] [-root <site>]",
+	"hugo-publisher images compress <src> <dst>",
+	"hugo-publisher indexnow submit [-root <site>] <url>...",
+	"hugo-publisher indexnow resubmit -root <site> [-dir <posts>] [-force]",
+	"filter flags: -status draft|scheduled|published|expired, -tags a,b, -keywords a,b, -author, -from <date>, -to <date>, -cover with|without, -subdir <dir>",
+	"post flags: -content <markdown> | -content-file <file, - for stdin>, -description, -author, -cover, -tags a,b, -weight, -slug, -keywords a,b, -hidden, -draft, -publish-date, -expiry-date, -root <site>",
+}
+
+// isCLI reports whether the command line asks for a subcommand
+func isCLI(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	_, ok := cliCommands[args[0]]
+	return ok
+}
+
+// runCLI runs the subcommand in args and prints its result, or an error
+// object, as JSON on stdout. It returns the process exit code.
+func runCLI(args []string) int {
+	// Warnings go to stderr so stdout stays valid JSON
+	a := NewApp()
+	a.warnings = os.Stderr
+	if err := a.settings.load(); err != nil {
+		return printCLI(os.Stdout, nil, err)
+	}
+	if _, err := a.schedule.load(time.Now()); err != nil {
+		return printCLI(os.Stdout, nil, err)
+	}
+	if err := a.outbox.Load(); err != nil {
+		return printCLI(os.Stdout, nil, err)
+	}
+	if err := a.resubmits.load(); err != nil {
+		return printCLI(os.Stdout, nil, err)
+	}
+	if err := a.index.Load(); err != nil {
+		a.warnf("Failed to load post index: %v", err)
+	}
+
+	result, err := cliCommands[args[0]](a, args[1:])
+	if err == nil {
+		// Send what the command queued now; failures stay queued for the app
+		a.outbox.Flush(context.Background(), appSender{app: a})
+	}
+	return printCLI(os.Stdout, result, err)
+}
+
+// printCLI writes a command result as JSON and returns the exit code
+func printCLI(w io.Writer, result interface{}, err error) int {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err != nil {
+		encoder.Encode(map[string]string{"error": err.Error()})
+		return 1
+	}
+	if encodeErr := encoder.Encode(result); encodeErr != nil {
+		fmt.Fprintln(os.Stderr, encodeErr)
+		return 1
+	}
+	return 0
+}
+
+// postsDir defaults an empty -dir to the active profile's content directory
+func (a *App) postsDir(dir *string) error {
+	if *dir == "" {
+		*dir = a.settings.active().ContentDir
+	}
+	if *dir == "" {
+		return errors.New("-dir is required unless the site profile sets contentDir")
+	}
+	return nil
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+// loadSiteRoot loads the Hugo configuration of root, if it has one
+func (a *App) loadSiteRoot(root string) error {
+	if root == "" {
+		return nil
+	}
+	if _, err := a.LoadSiteConfig(root); err != nil && !errors.Is(err, hugoconfig.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// postFlags are the post fields accepted by `new` and `edit`
+type postFlags struct {
+	flags       *flag.FlagSet
+	root        *string
+	dir         *string
+	title       *string
+	content     *string
+	contentFile *string
+	description *string
+	author      *string
+	cover       *string
+	tags        *string
+	weight      *int
+	slug        *string
+	keywords    *string
+	hidden      *bool
+	draft       *bool
+	publishDate *string
+	expiryDate  *string
+}
+
+func newPostFlags(name string) *postFlags {
+	flags := newFlagSet(name)
+	return &postFlags{
+		flags:       flags,
+		root:        flags.String("root", "", "Hugo site root directory"),
+		dir:         flags.String("dir", "", "posts directory, the profile's contentDir by default"),
+		title:       flags.String("title", "", "title"),
+		content:     flags.String("content", "", "markdown body"),
+		contentFile: flags.String("content-file", "", "file holding the markdown body, - for stdin"),
+		description: flags.String("description", "", "description"),
+		author:      flags.String("author", "", "author, defaults to the profile's default author"),
+		cover:       flags.String("cover", "", "cover image path"),
+		tags:        flags.String("tags", "", "comma separated tags"),
+		weight:      flags.Int("weight", 0, "weight"),
+		slug:        flags.String("slug", "", "slug"),
+		keywords:    flags.String("keywords", "", "comma separated keywords"),
+		hidden:      flags.Bool("hidden", false, "hide the cover in lists"),
+		draft:       flags.Bool("draft", false, "save as draft"),
+		publishDate: flags.String("publish-date", "", "publish date, e.g. 2025-01-02T08:00:00+08:00"),
+		expiryDate:  flags.String("expiry-date", "", "expiry date"),
+	}
+}
+
+// parse reads args and loads the site configuration; it returns the names
+// of the flags given on the command line
+func (p *postFlags) parse(a *App, args []string) (map[string]bool, error) {
+	if err := p.flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := a.postsDir(p.dir); err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool)
+	p.flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set, a.loadSiteRoot(*p.root)
+}
+
+// body returns the markdown body given with -content or -content-file
+func (p *postFlags) body() (string, error) {
+	if *p.contentFile == "" {
+		return *p.content, nil
+	}
+	var data []byte
+	var err error
+	if *p.contentFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*p.contentFile)
+	}
+	return string(data), err
+}
+
+// newCommand handles `hugo-publisher new`
+func newCommand(a *App, args []string) (interface{}, error) {
+	p := newPostFlags("new")
+	if _, err := p.parse(a, args); err != nil {
+		return nil, err
+	}
+	if *p.title == "" {
+		return nil, errors.New("-title is required")
+	}
+	content, err := p.body()
+	if err != nil {
+		return nil, err
+	}
+
+	post := formPost(*p.title, content, *p.description, *p.author, *p.cover, posts.SplitList(*p.tags), *p.weight,
+		*p.slug, *p.keywords, *p.hidden, *p.draft, *p.publishDate, *p.expiryDate)
+	filePath, err := a.createPost(*p.dir, post)
+	if err != nil {
+		return nil, err
+	}
+	return a.store(*p.dir).Info(filePath), nil
+}
+
+// editCommand handles `hugo-publisher edit`. Fields not given on the
+// command line keep their current value.
+func editCommand(a *App, args []string) (interface{}, error) {
+	p := newPostFlags("edit")
+	id := p.flags.String("id", "", "post ID as printed by list")
+	set, err := p.parse(a, args)
+	if err != nil {
+		return nil, err
+	}
+	if *id == "" {
+		return nil, errors.New("-id is required")
+	}
+
+	store := a.store(*p.dir)
+	filePath, err := store.Resolve(*id)
+	if err != nil {
+		return nil, err
+	}
+	page, err := store.Page(filePath)
+	if err != nil {
+		return nil, err
+	}
+	meta := page.Meta
+
+	title := meta.Title
+	if set["title"] {
+		title = *p.title
+	}
+	content := page.Body
+	if set["content"] || set["content-file"] {
+		if content, err = p.body(); err != nil {
+			return nil, err
+		}
+	}
+	description := meta.Description
+	if set["description"] {
+		description = *p.description
+	}
+	// An empty author keeps every author the post lists
+	author := ""
+	if set["author"] {
+		author = *p.author
+	}
+	cover := meta.Cover.Image
+	if set["cover"] {
+		cover = *p.cover
+	}
+	tags := meta.Tags
+	if set["tags"] {
+		tags = posts.SplitList(*p.tags)
+	}
+	weight := meta.Weight
+	if set["weight"] {
+		weight = *p.weight
+	}
+	slug := meta.Slug
+	if set["slug"] {
+		slug = *p.slug
+	}
+	keywords := strings.Join(meta.Keywords, ", ")
+	if set["keywords"] {
+		keywords = *p.keywords
+	}
+	hidden := meta.Cover.HiddenInList == nil || *meta.Cover.HiddenInList
+	if set["hidden"] {
+		hidden = *p.hidden
+	}
+	draft := meta.Draft
+	if set["draft"] {
+		draft = *p.draft
+	}
+	publishDate := meta.PublishDate
+	if set["publish-date"] {
+		publishDate = *p.publishDate
+	}
+	expiryDate := meta.ExpiryDate
+	if set["expiry-date"] {
+		expiryDate = *p.expiryDate
+	}
+
+	post := formPost(title, content, description, author, cover, tags, weight,
+		slug, keywords, hidden, draft, publishDate, expiryDate)
+	// A changed slug moves the post
+	if filePath, err = a.updatePost(*id, *p.dir, post); err != nil {
+		return nil, err
+	}
+	return store.Info(filePath), nil
+}
+
+// listCommand handles `hugo-publisher list`; by default every post is listed
+func listCommand(a *App, args []string) (interface{}, error) {
+	flags := newFlagSet("list")
+	root := flags.String("root", "", "Hugo site root directory")
+	dir := flags.String("dir", "", "posts directory, the profile's contentDir by default")
+	search := flags.String("search", "", "search text")
+	filter := filterFlags(flags)
+	sortKey := flags.String("sort", "", "lastmod, date, title, weight or wordCount")
+	order := flags.String("order", "", "asc or desc")
+	page := flags.Int("page", 1, "page number")
+	size := flags.Int("size", 0, "posts per page, 0 lists all")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := a.postsDir(dir); err != nil {
+		return nil, err
+	}
+	if err := a.loadSiteRoot(*root); err != nil {
+		return nil, err
+	}
+
+	return a.store(*dir).List(posts.Query{Filter: filter(), Search: *search, Sort: *sortKey, Order: *order, Page: *page, PageSize: *size})
+}
+
+// filterFlags defines the flags of a posts.Filter on flags; the returned
+// function builds the filter once they are parsed
+func filterFlags(flags *flag.FlagSet) func() posts.Filter {
+	status := flags.String("status", "", "draft, scheduled, published or expired")
+	tags := flags.String("tags", "", "only posts with all of these tags, comma separated")
+	keywords := flags.String("keywords", "", "only posts with all of these keywords, comma separated")
+	author := flags.String("author", "", "only posts by this author")
+	from := flags.String("from", "", "only posts dated on or after, e.g. 2024-01-01")
+	to := flags.String("to", "", "only posts dated on or before, e.g. 2024-12-31")
+	cover := flags.String("cover", "", "with or without a cover image")
+	subdir := flags.String("subdir", "", "only posts below this directory of -dir")
+	return func() posts.Filter {
+		return posts.Filter{
+			Status:    *status,
+			Tags:      posts.SplitList(*tags),
+			Keywords:  posts.SplitList(*keywords),
+			Author:    *author,
+			From:      *from,
+			To:        *to,
+			Cover:     *cover,
+			Directory: *subdir,
+		}
+	}
+}
+
+// searchCommand handles `hugo-publisher search`; by default every match is
+// returned
+func searchCommand(a *App, args []string) (interface{}, error) {
+	flags := newFlagSet("search")
+	dir := flags.String("dir", "", "posts directory, the profile's contentDir by default")
+	filter := filterFlags(flags)
+	page := flags.Int("page", 1, "page number")
+	size := flags.Int("size", 0, "posts per page, 0 returns all")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := a.postsDir(dir); err != nil {
+		return nil, err
+	}
+	text := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("usage: hugo-publisher search [-dir <posts>] <words>...")
+	}
+
+	return a.store(*dir).Search(posts.SearchQuery{Filter: filter(), Text: text, Page: *page, PageSize: *size})
+}
+
+// deleteCommand handles `hugo-publisher delete`
+func deleteCommand(a *App, args []string) (interface{}, error) {
+	flags := newFlagSet("delete")
+	root := flags.String("root", "", "Hugo site root directory")
+	dir := flags.String("dir", "", "posts directory, the profile's contentDir by default")
+	id := flags.String("id", "", "post ID as printed by list")
+	images := flags.String("images", "", "image upload directory, the profile's imageDir by default; referenced images in it are deleted too")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *id == "" {
+		return nil, errors.New("-id is required")
+	}
+	if err := a.postsDir(dir); err != nil {
+		return nil, err
+	}
+	if *images == "" {
+		*images = a.settings.active().ImageDir
+	}
+	if err := a.loadSiteRoot(*root); err != nil {
+		return nil, err
+	}
+
+	if err := a.DeletePost(*id, *dir, *images, *root); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"id": *id, "deleted": true}, nil
+}
+
+// imagesCommand handles `hugo-publisher images compress <src> <dst>`
+func imagesCommand(a *App, args []string) (interface{}, error) {
+	if len(args) != 3 || args[0] != "compress" {
+		return nil, errors.New("usage: hugo-publisher images compress <src> <dst>")
+	}
+	src, dst := args[1], args[2]
+	if err := a.CompressImage(src, dst); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"src": src, "dst": dst, "size": info.Size()}, nil
+}
+
+// submitReport counts how the submissions queued since a command started
+// went, per URL and notifier
+type submitReport struct {
+	Submitted int `json:"submitted"` // 已提交
+	Pending   int `json:"pending"`   // 等待重试，由应用在后台继续提交
+	Failed    int `json:"failed"`    // 失败
+}
+
+// submissionReport flushes the outbox and counts the submissions of urls
+// queued for profile since since
+func (a *App) submissionReport(profile string, urls []string, since time.Time) submitReport {
+	a.outbox.Flush(context.Background(), appSender{app: a})
+
+	queued := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		queued[u] = true
+	}
+	var report submitReport
+	for _, entry := range a.outbox.List() {
+		if entry.Profile != profile || !queued[entry.URL] || entry.QueuedAt.Before(since) {
+			continue
+		}
+		switch entry.Status {
+		case notify.SubmissionSubmitted:
+			report.Submitted++
+		case notify.SubmissionPending:
+			report.Pending++
+		case notify.SubmissionFailed:
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// resubmitReport is the output of `indexnow resubmit`
+type resubmitReport struct {
+	ResubmitResult
+	submitReport
+}
+
+// indexNowCommand handles `hugo-publisher indexnow <subcommand>`
+func indexNowCommand(a *App, args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, errors.New("usage: hugo-publisher indexnow submit|resubmit")
+	}
+
+	switch args[0] {
+	case "submit":
+		flags := newFlagSet("indexnow submit")
+		root := flags.String("root", "", "Hugo site root directory")
+		if err := flags.Parse(args[1:]); err != nil {
+			return nil, err
+		}
+		urls := flags.Args()
+		if len(urls) == 0 {
+			return nil, errors.New("usage: hugo-publisher indexnow submit [-root <site>] <url>...")
+		}
+		if err := a.loadSiteRoot(*root); err != nil {
+			return nil, err
+		}
+
+		if err := requireNotifiers(a.site()); err != nil {
+			return nil, err
+		}
+		since := time.Now()
+		if err := a.submitURLs(urls...); err != nil {
+			return nil, err
+		}
+		return struct {
+			URLs []string `json:"urls"`
+			submitReport
+		}{urls, a.submissionReport(a.site().Name, urls, since)}, nil
+
+	case "resubmit":
+		flags := newFlagSet("indexnow resubmit")
+		root := flags.String("root", "", "Hugo site root directory")
+		dir := flags.String("dir", "", "posts directory, used when the site has no public/sitemap.xml; the profile's contentDir by default")
+		force := flags.Bool("force", false, "submit every URL, not only new and changed ones")
+		if err := flags.Parse(args[1:]); err != nil {
+			return nil, err
+		}
+		if *dir == "" {
+			*dir = a.settings.active().ContentDir
+		}
+		if err := a.loadSiteRoot(*root); err != nil {
+			return nil, err
+		}
+
+		since := time.Now()
+		result, err := a.ResubmitSite(*root, *dir, *force)
+		if err != nil {
+			return nil, err
+		}
+		return resubmitReport{result, a.submissionReport(a.site().Name, result.URLs, since)}, nil
+	}
+	return nil, fmt.Errorf("unknown indexnow command: %s", args[0])
+}
+
+// helpCommand handles `hugo-publisher help`
+func helpCommand(a *App, args []string) (interface{}, error) {
+	return map[string][]string{"usage": cliUsage}, nil
+}
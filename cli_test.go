@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"hugo-publisher/pkg/posts"
+)
+
+// testApp returns an app whose settings live in a temporary directory
+func testApp(t *testing.T) *App {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	a := NewApp()
+	if err := a.settings.load(); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestEditKeepsAuthors(t *testing.T) {
+	a := testApp(t)
+	if err := a.SaveProfile(SiteProfile{Name: "blog", DefaultAuthor: "Default", PostLayout: "{{slug}}.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	post := "---\ntitle: Old\nslug: post\nauthor:\n  - Alice\n  - Bob\n---\n\nbody\n"
+	if err := os.WriteFile(filepath.Join(dir, "post.md"), []byte(post), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := editCommand(a, []string{"-dir", dir, "-id", "post.md", "-title", "New"}); err != nil {
+		t.Fatal(err)
+	}
+	page, err := a.store(dir).Page(filepath.Join(dir, "post.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Meta.Title != "New" {
+		t.Errorf("title = %q, want New", page.Meta.Title)
+	}
+	if got := page.Meta.Author; !slices.Equal(got, []string{"Alice", "Bob"}) {
+		t.Errorf("authors = %q, want [Alice Bob]", got)
+	}
+
+	// -author replaces them
+	if _, err := editCommand(a, []string{"-dir", dir, "-id", "post.md", "-author", "Carol"}); err != nil {
+		t.Fatal(err)
+	}
+	if page, err = a.store(dir).Page(filepath.Join(dir, "post.md")); err != nil {
+		t.Fatal(err)
+	}
+	if got := page.Meta.Author; !slices.Equal(got, []string{"Carol"}) {
+		t.Errorf("authors = %q, want [Carol]", got)
+	}
+}
+
+func TestNoNotifiersNoWarnings(t *testing.T) {
+	a := testApp(t)
+	var warnings bytes.Buffer
+	a.warnings = &warnings
+	if err := a.SaveProfile(SiteProfile{Name: "blog", BaseURL: "https://example.com/", PostLayout: "{{slug}}.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := newCommand(a, []string{"-dir", dir, "-title", "Hello", "-slug", "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := editCommand(a, []string{"-dir", dir, "-id", "hello.md", "-slug", "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deleteCommand(a, []string{"-dir", dir, "-id", "hi.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("a site without notifiers got warnings:\n%s", warnings.String())
+	}
+
+	// Asking for a submission is an error though
+	if _, err := indexNowCommand(a, []string{"submit", "https://example.com/hello/"}); err == nil {
+		t.Error("submit succeeded without a notifier")
+	}
+}
+
+func TestProfileDirectoriesAreDefaults(t *testing.T) {
+	a := testApp(t)
+	if _, err := listCommand(a, nil); err == nil {
+		t.Error("list ran without -dir or a profile contentDir")
+	}
+
+	dir := t.TempDir()
+	if err := a.SaveProfile(SiteProfile{Name: "blog", ContentDir: dir, PostLayout: "{{slug}}.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newCommand(a, []string{"-title", "Hello", "-slug", "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hello.md")); err != nil {
+		t.Errorf("new did not write to the profile's contentDir: %v", err)
+	}
+	result, err := listCommand(a, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := result.(posts.ListResult); list.TotalCount != 1 {
+		t.Errorf("list found %d posts in the profile's contentDir, want 1", list.TotalCount)
+	}
+}
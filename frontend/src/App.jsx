@@ -3,10 +3,12 @@ import './App.css';
 import MdEditor from 'react-markdown-editor-lite';
 import MarkdownIt from 'markdown-it';
 import 'react-markdown-editor-lite/lib/index.css';
-import { CompressImage, SavePost, SelectDirectory, SelectImageDirectory, SaveAndCompressImage, CheckTitleDuplicate, DeletePost, UpdatePost, ListPosts, LoadPost, LoadImageAsBase64 } from "../wailsjs/go/main/App";
+import { CompressImage, SavePost, SelectDirectory, SelectImageDirectory, SaveAndCompressImage, CheckTitleDuplicate, DeletePost, UpdatePost, ListPosts, LoadPost, LoadImageAsBase64, LoadSiteConfig, ResolveImageTarget, GetActiveProfile, GetMissedSchedules, DismissMissedSchedules, WatchDirectories } from "../wailsjs/go/main/App";
+import { EventsOn } from "../wailsjs/runtime/runtime";
 import { useTheme } from './ThemeProvider';
-import { SunIcon, MoonIcon, XMarkIcon, PencilIcon, TrashIcon, Bars3Icon } from '@heroicons/react/24/outline';
+import { SunIcon, MoonIcon, XMarkIcon, PencilIcon, TrashIcon, Bars3Icon, SignalIcon } from '@heroicons/react/24/outline';
 import PostListModal from './PostListModal';
+import SubmissionsModal from './SubmissionsModal';
 
 // 初始化markdown解析器
 const mdParser = new MarkdownIt();
@@ -33,7 +35,8 @@ function App() {
     const [title, setTitle] = useState('');
     const [description, setDescription] = useState('');
     const [tags, setTags] = useState('');
-    const [author, setAuthor] = useState('Aries');
+    const [author, setAuthor] = useState('');
+    const [defaultAuthor, setDefaultAuthor] = useState(''); // 当前站点配置的默认作者，作者留空时由后端填入
     const [weight, setWeight] = useState(1);
     const [content, setContent] = useState('');
     const [coverImage, setCoverImage] = useState(null);
@@ -45,7 +48,7 @@ function App() {
     const [titleDuplicate, setTitleDuplicate] = useState(false); // 标题重复状态
     const [duplicatePath, setDuplicatePath] = useState(''); // 重复文件路径
     const [isEditMode, setIsEditMode] = useState(false); // 编辑模式状态
-    const [originalTitle, setOriginalTitle] = useState(''); // 原始标题（用于更新）
+    const [originalId, setOriginalId] = useState(''); // 原始文章ID（用于更新）
     const [posts, setPosts] = useState([]); // 文章列表
     const [loadingPosts, setLoadingPosts] = useState(false); // 加载状态
     const [selectedPost, setSelectedPost] = useState(null); // 当前选中的文章
@@ -53,10 +56,14 @@ function App() {
     const [totalPosts, setTotalPosts] = useState(0); // 总文章数
     const [pageSize] = useState(5); // 每页显示的文章数
     const [isPostListModalOpen, setIsPostListModalOpen] = useState(false); // 文章列表模态框状态
+    const [isSubmissionsModalOpen, setIsSubmissionsModalOpen] = useState(false); // 搜索引擎提交记录模态框状态
     const [autoSaveDirectories, setAutoSaveDirectories] = useState(true); // 是否自动保存目录
     const [isCoverHidden, setIsCoverHidden] = useState(true); // 封面是否在列表中隐藏
     const [slug, setSlug] = useState(''); // 自定义URL
     const [keywords, setKeywords] = useState(''); // 关键词
+    const [draft, setDraft] = useState(false); // 是否保存为草稿
+    const [publishDate, setPublishDate] = useState(''); // 定时发布时间
+    const [expiryDate, setExpiryDate] = useState(''); // 过期时间
     
     // 应用启动时加载保存的目录
     useEffect(() => {
@@ -105,6 +112,79 @@ function App() {
         saveDirectoriesToLocalStorage();
     }, [saveDirectory, imageDirectory, rootDirectory, saveDirectoriesToLocalStorage]);
 
+    // 启用站点配置：默认作者作为新文章作者的初始值，配置了文章目录和图片目录时改用这两个目录
+    const applyProfile = useCallback((profile) => {
+        const name = (profile && profile.defaultAuthor) || '';
+        setDefaultAuthor(name);
+        setAuthor((current) => current || name);
+        if (profile && profile.contentDir) setSaveDirectory(profile.contentDir);
+        if (profile && profile.imageDir) setImageDirectory(profile.imageDir);
+    }, []);
+
+    useEffect(() => {
+        GetActiveProfile().then(applyProfile).catch((error) => {
+            console.warn('Failed to load site profile:', error);
+        });
+        return EventsOn('profile:activated', applyProfile);
+    }, [applyProfile]);
+
+    // 根目录改变时读取 Hugo 站点配置（baseURL、permalinks 等）
+    useEffect(() => {
+        if (!rootDirectory) return;
+        LoadSiteConfig(rootDirectory).catch((error) => {
+            console.warn('Failed to load Hugo site config:', error);
+        });
+    }, [rootDirectory]);
+
+    // 启动时提示应用关闭期间错过或失败的定时发布
+    useEffect(() => {
+        GetMissedSchedules().then((missed) => {
+            if (!missed || missed.length === 0) return;
+            const lines = missed.map((entry) => {
+                const state = entry.status === 'failed' ? `发布失败：${entry.error}` : '已补发';
+                return `- ${entry.title || entry.id}（计划于 ${new Date(entry.publishAt).toLocaleString()}，${state}）`;
+            });
+            alert('以下定时发布在应用关闭期间错过了计划时间：\n' + lines.join('\n'));
+            return DismissMissedSchedules();
+        }).catch((error) => {
+            console.warn('Failed to load missed schedules:', error);
+        });
+    }, []);
+
+    // 定时发布的文章上线后刷新文章列表
+    useEffect(() => {
+        return EventsOn('schedule:published', () => {
+            loadPosts(currentPage);
+        });
+    });
+
+    // 文章的链接未能提交给搜索引擎时（如 IndexNow key 文件不存在）提示用户
+    useEffect(() => {
+        return EventsOn('notify:failed', (message) => {
+            alert('搜索引擎通知未发送：' + message);
+        });
+    }, []);
+
+    // 监视文章目录和图片目录，在编辑器或 git pull 修改文件后刷新文章列表
+    useEffect(() => {
+        WatchDirectories(saveDirectory, imageDirectory).catch((error) => {
+            console.warn('Failed to watch directories:', error);
+        });
+    }, [saveDirectory, imageDirectory]);
+
+    useEffect(() => {
+        const offPosts = EventsOn('posts:changed', (event) => {
+            if (event.directory === saveDirectory) loadPosts(currentPage);
+        });
+        const offImages = EventsOn('images:changed', () => {
+            loadPosts(currentPage);
+        });
+        return () => {
+            offPosts();
+            offImages();
+        };
+    });
+
     // 检查标题是否重复
     useEffect(() => {
         const checkDuplicate = async () => {
@@ -180,8 +260,8 @@ function App() {
         
         setLoadingPosts(true);
         try {
-            // 调用新的分页和搜索方法（空搜索字符串表示不搜索）
-            const result = await ListPosts(saveDirectory, rootDirectory, page, pageSize, "");
+            // 按修改时间列出全部文章，不筛选
+            const result = await ListPosts(saveDirectory, rootDirectory, { page, pageSize });
                         
             const postList = result.posts || [];
             const totalCount = result.totalCount || 0;
@@ -216,8 +296,8 @@ function App() {
         }
     };
 
-    // 编辑文章
-    const editPost = async (postTitle) => {
+    // 编辑文章（postId 为 ListPosts 返回的文章ID）
+    const editPost = async (postId) => {
         if (!saveDirectory) {
             alert('请先选择保存目录');
             return;
@@ -225,23 +305,26 @@ function App() {
 
         try {
             // Set selected post
-            setSelectedPost(postTitle);
+            setSelectedPost(postId);
             
             // Load post content
-            const postContent = await LoadPost(postTitle, saveDirectory);
+            const postContent = await LoadPost(postId, saveDirectory);
             
             // Parse front matter and content
             const parsedData = parseMarkdownContent(postContent);
             
             // Fill form fields
-            setTitle(parsedData.title || postTitle);
+            setTitle(parsedData.title || '');
             setContent(parsedData.content || '');
             setDescription(parsedData.description || '');
-            setAuthor(parsedData.author || 'Aries');
+            setAuthor(parsedData.author || '');
             setTags(parsedData.tags || '');
             setWeight(parsedData.weight || 1);
             setSlug(parsedData.slug || '');
             setKeywords(parsedData.keywords || '');
+            setDraft(parsedData.draft || false);
+            setPublishDate(parsedData.publishDate || '');
+            setExpiryDate(parsedData.expiryDate || '');
             
             // 设置封面图片相关状态
             if (parsedData.coverImage) {
@@ -251,7 +334,14 @@ function App() {
                 setCoverImage(null);
                 // 加载封面图片的base64数据用于预览
                 try {
-                    const base64Data = await LoadImageAsBase64(parsedData.coverImage, rootDirectory);
+                    // 页面包中的封面是相对于 index.md 的文件名
+                    let coverPath = parsedData.coverImage;
+                    let coverRoot = rootDirectory;
+                    if (!coverPath.startsWith('/') && postId.includes('/')) {
+                        coverPath = `${saveDirectory}/${postId.substring(0, postId.lastIndexOf('/'))}/${coverPath}`;
+                        coverRoot = ''; // 已是绝对路径，不再拼接 static 目录
+                    }
+                    const base64Data = await LoadImageAsBase64(coverPath, coverRoot);
                     setCoverImageBase64(base64Data);
                 } catch (error) {
                     console.error('Failed to load cover image:', error);
@@ -269,7 +359,7 @@ function App() {
             
             // Enter edit mode
             setIsEditMode(true);
-            setOriginalTitle(postTitle);
+            setOriginalId(postId);
         } catch (error) {
             console.error('Failed to load post:', error);
             alert('加载文章失败：' + (error.message || error));
@@ -281,14 +371,25 @@ function App() {
         const result = {
             title: '',
             description: '',
-            author: 'Aries',
+            author: '',
             tags: '',
             weight: 1,
             content: '',
             slug: '',
             keywords: '',
             coverImage: '', // 添加封面图片字段
-            isCoverHidden: true // 添加封面显示控制字段
+            isCoverHidden: true, // 添加封面显示控制字段
+            draft: false,
+            publishDate: '',
+            expiryDate: ''
+        };
+
+        // front matter 中的时间转换为 datetime-local 输入框的格式
+        const toDateTimeLocal = (value) => {
+            const date = new Date(value.replace(/^"(.*)"$/, '$1'));
+            if (isNaN(date.getTime())) return '';
+            const pad = (n) => String(n).padStart(2, '0');
+            return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}T${pad(date.getHours())}:${pad(date.getMinutes())}`;
         };
 
         // Check if content has front matter
@@ -350,6 +451,12 @@ function App() {
                             }
                         }
                         result.keywords = keywordArray.join(', ');
+                    } else if (trimmedLine.startsWith('draft:')) {
+                        result.draft = trimmedLine.substring(6).trim() === 'true';
+                    } else if (trimmedLine.startsWith('publishDate:')) {
+                        result.publishDate = toDateTimeLocal(trimmedLine.substring(12).trim());
+                    } else if (trimmedLine.startsWith('expiryDate:')) {
+                        result.expiryDate = toDateTimeLocal(trimmedLine.substring(11).trim());
                     } else if (trimmedLine.startsWith('cover:')) {
                         // 进入 cover 块
                         inCoverBlock = true;
@@ -372,8 +479,8 @@ function App() {
         return result;
     };
 
-    // 删除文章
-    const deletePost = async (postTitle) => {
+    // 删除文章（postId 为文章ID，旧调用方式下也可以是标题）
+    const deletePost = async (postId, postTitle = postId) => {
         if (!saveDirectory) {
             alert('请先选择保存目录');
             return;
@@ -384,14 +491,14 @@ function App() {
         }
 
         try {
-            await DeletePost(postTitle, saveDirectory, imageDirectory, rootDirectory);
+            await DeletePost(postId, saveDirectory, imageDirectory, rootDirectory);
             alert('文章删除成功！');
             
             // 重新加载文章列表
             loadPosts();
             
             // 如果正在编辑这篇文章，退出编辑模式
-            if (isEditMode && originalTitle === postTitle) {
+            if (isEditMode && originalId === postId) {
                 exitEditMode();
             }
         } catch (error) {
@@ -410,19 +517,14 @@ function App() {
             // If there's a cover image, compress and save it
             let coverImagePathToUse = coverImagePath || ''; // 使用已存在的封面图片路径
             let imageSavePath = ''; // 用于保存图片的实际路径
-            if (coverImage && imageDirectory) {
+            if (coverImage) {
                 // Generate a unique filename for the image
                 const timestamp = new Date().getTime();
                 const imageName = `cover-${timestamp}${getFileExtension(coverImage.name)}`;
                 
-                // Create image save path with mixed separators as requested
-                // E.g., E:\workshop\xiaomizhou.net\ai-sites\static\images\uploads/cover-1761294707491.png
-                imageSavePath = `${imageDirectory}/${imageName}`.replace(/\//g, '\\'); // 先将所有正斜杠替换为反斜杠
-                // 然后将最后一个反斜杠替换为正斜杠，以匹配您要求的格式
-                const lastBackslashIndex = imageSavePath.lastIndexOf('\\');
-                if (lastBackslashIndex !== -1) {
-                    imageSavePath = imageSavePath.substring(0, lastBackslashIndex) + '/' + imageSavePath.substring(lastBackslashIndex + 1);
-                }
+                // 由后端决定保存位置：图片上传目录，或页面包（bundle）目录
+                const target = await ResolveImageTarget(isEditMode ? originalId : '', title, slug, saveDirectory, imageDirectory, imageName);
+                imageSavePath = target.path;
                 
                 // Read the file as base64 string
                 const base64Data = await readFileAsBase64(coverImage);
@@ -430,8 +532,8 @@ function App() {
                 // Compress and save the image
                 await SaveAndCompressImage(base64Data, coverImage.name, imageSavePath);
                 
-                // Convert image path to URL path for front matter - 使用固定的/images/uploads/路径格式
-                coverImagePathToUse = `/images/uploads/${imageName}`;
+                // 封面在 front matter 中的地址（/images/uploads/... 或页面包内的文件名）
+                coverImagePathToUse = target.url;
             }
 
             // Parse tags from comma-separated string to array
@@ -441,7 +543,7 @@ function App() {
             const safeTitle = title || '';
             const safeContent = content || '';
             const safeDescription = description || '';
-            const safeAuthor = author || 'Aries';
+            const safeAuthor = (author || '').trim(); // 留空时使用站点配置的默认作者
             const safeCoverImagePath = coverImagePathToUse || '';
             const safeSaveDirectory = saveDirectory || '';
             const safeTagsArray = tagsArray || [];
@@ -467,14 +569,14 @@ function App() {
 
             if (isEditMode) {
                 // Update existing post
-                await UpdatePost(originalTitle, safeTitle, safeContent, safeDescription, safeAuthor, safeCoverImagePath, safeSaveDirectory, safeTagsArray, safeWeight, safeSlug, safeKeywords, safeIsCoverHidden);
+                await UpdatePost(originalId, safeTitle, safeContent, safeDescription, safeAuthor, safeCoverImagePath, safeSaveDirectory, safeTagsArray, safeWeight, safeSlug, safeKeywords, safeIsCoverHidden, draft, publishDate || '', expiryDate || '');
                 alert('文章更新成功！');
                 // Exit edit mode
                 setIsEditMode(false);
-                setOriginalTitle('');
+                setOriginalId('');
             } else {
                 // Save new post
-                await SavePost(safeTitle, safeContent, safeDescription, safeAuthor, safeCoverImagePath, safeSaveDirectory, safeTagsArray, safeWeight, safeSlug, safeKeywords, safeIsCoverHidden);
+                await SavePost(safeTitle, safeContent, safeDescription, safeAuthor, safeCoverImagePath, safeSaveDirectory, safeTagsArray, safeWeight, safeSlug, safeKeywords, safeIsCoverHidden, draft, publishDate || '', expiryDate || '');
                 alert('文章发布成功！');
             }
             
@@ -517,19 +619,18 @@ function App() {
                 // Generate a unique filename for the image
                 const timestamp = new Date().getTime();
                 const imageName = `editor-${timestamp}${getFileExtension(file.name)}`;
-                const imagePath = `${uploadDirectory}/${imageName}`;
+                
+                // 由后端决定保存位置：图片上传目录，或页面包（bundle）目录
+                const target = await ResolveImageTarget(isEditMode ? originalId : '', title, slug, saveDirectory, uploadDirectory, imageName);
                 
                 // Read the file as base64 string
                 const base64Data = await readFileAsBase64(file);
                 
                 // Compress and save the image
-                await SaveAndCompressImage(base64Data, file.name, imagePath);
-                
-                // Convert image path to URL path - 使用固定的/images/uploads/路径格式
-                let imageUrl = `/images/uploads/${imageName}`;
+                await SaveAndCompressImage(base64Data, file.name, target.path);
                 
                 // 返回图片URL
-                return imageUrl;
+                return target.url;
             } catch (error) {
                 console.error('Failed to upload image:', error);
                 alert('图片上传失败：' + (error.message || error));
@@ -574,7 +675,7 @@ function App() {
             alert('选择图片保存目录失败: ' + (error.message || error));
             throw error;
         }
-    }, [imageDirectory, setImageDirectory]);
+    }, [imageDirectory, setImageDirectory, isEditMode, originalId, title, slug, saveDirectory]);
 
     // 清空输入框的函数
     const clearInput = (setter) => () => setter('');
@@ -584,7 +685,7 @@ function App() {
         setTitle('');
         setDescription('');
         setTags('');
-        setAuthor('Aries');
+        setAuthor(defaultAuthor);
         setWeight(1);
         setContent('');
         setCoverImage(null);
@@ -593,27 +694,22 @@ function App() {
         setIsCoverHidden(true); // 重置封面显示选项为隐藏
         setSlug(''); // 重置自定义URL
         setKeywords(''); // 重置关键词
+        setDraft(false); // 重置草稿状态
+        setPublishDate(''); // 重置定时发布时间
+        setExpiryDate(''); // 重置过期时间
         // 不清空目录选择，以便连续操作
     };
 
-    // 进入编辑模式
-    const enterEditMode = (postTitle) => {
-        setIsEditMode(true);
-        setOriginalTitle(postTitle);
-        setTitle(postTitle);
-        // 这里可以添加加载文章内容的逻辑
-    };
-
     // 退出编辑模式
     const exitEditMode = () => {
         setIsEditMode(false);
-        setOriginalTitle('');
+        setOriginalId('');
         clearForm();
     };
 
     // 处理从文章列表模态框选择的文章
-    const handleEditPostFromModal = (postTitle) => {
-        editPost(postTitle);
+    const handleEditPostFromModal = (postId) => {
+        editPost(postId);
     };
 
 
@@ -644,6 +740,14 @@ function App() {
                                         <Bars3Icon className="h-5 w-5" />
                                     </button>
                                 )}
+                                {/* 搜索引擎提交记录按钮 */}
+                                <button 
+                                    onClick={() => setIsSubmissionsModalOpen(true)}
+                                    className="p-2 rounded-full bg-gray-200 dark:bg-gray-700 text-gray-700 dark:text-gray-200 hover:bg-gray-300 dark:hover:bg-gray-600 transition-colors duration-200"
+                                    title="搜索引擎提交记录"
+                                >
+                                    <SignalIcon className="h-5 w-5" />
+                                </button>
                                 <ThemeToggle />
                             </div>
                         </div>
@@ -713,22 +817,22 @@ function App() {
                                                         <div 
                                                             key={index} 
                                                             className={`flex justify-between items-center p-3 border-b border-gray-200 dark:border-gray-600 last:border-b-0 ${
-                                                                selectedPost === post.title 
+                                                                selectedPost === post.id 
                                                                     ? 'bg-blue-100 dark:bg-blue-900' 
                                                                     : 'hover:bg-gray-100 dark:hover:bg-gray-600'
                                                             }`}
                                                         >
                                                             <div className="flex-1 flex items-center min-w-0">
-                                                                {post.coverImageBase64 && (
+                                                                {post.coverThumbnail && (
                                                                     <img 
-                                                                        src={`data:image/auto;base64,${post.coverImageBase64}`} 
+                                                                        src={post.coverThumbnail} 
                                                                         alt={post.title}
                                                                         className="w-16 h-10 object-cover rounded mr-4 flex-shrink-0"
                                                                     />
                                                                 )}
                                                                 <span 
                                                                     className="cursor-pointer text-blue-600 hover:text-blue-800 dark:text-blue-400 dark:hover:text-blue-300 truncate"
-                                                                    onClick={() => editPost(post.title)}
+                                                                    onClick={() => editPost(post.id)}
                                                                     title={post.title}
                                                                 >
                                                                     {post.title}
@@ -736,14 +840,14 @@ function App() {
                                                             </div>
                                                             <div className="flex space-x-2 ml-4">
                                                                 <button 
-                                                                    onClick={() => editPost(post.title)}
+                                                                    onClick={() => editPost(post.id)}
                                                                     className="text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300 p-1"
                                                                     title="编辑"
                                                                 >
                                                                     <PencilIcon className="h-4 w-4" />
                                                                 </button>
                                                                 <button 
-                                                                    onClick={() => deletePost(post.title)}
+                                                                    onClick={() => deletePost(post.id, post.title)}
                                                                     className="text-red-500 hover:text-red-700 dark:text-red-400 dark:hover:text-red-300 p-1"
                                                                     title="删除"
                                                                 >
@@ -899,9 +1003,9 @@ function App() {
                                                     value={author}
                                                     onChange={(e) => setAuthor(e.target.value)}
                                                     className="border border-gray-300 dark:border-gray-600 dark:bg-gray-700 dark:text-white px-4 py-2 rounded-lg w-full focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent pr-10"
-                                                    placeholder="请输入作者姓名"
+                                                    placeholder={defaultAuthor ? `留空则使用默认作者：${defaultAuthor}` : '请输入作者姓名'}
                                                 />
-                                                {author && author !== 'Aries' && (
+                                                {author && author !== defaultAuthor && (
                                                     <button 
                                                         onClick={clearInput(setAuthor)}
                                                         className="absolute right-3 top-1/2 transform -translate-y-1/2 text-gray-500 hover:text-gray-700 dark:text-gray-400 dark:hover:text-gray-200"
@@ -1057,6 +1161,38 @@ function App() {
                                         </div>
                                     </div>
                                 </div>
+                                <div className="w-full flex flex-col sm:flex-row gap-4">
+                                    <div className="flex items-center sm:w-1/5">
+                                        <input
+                                            type="checkbox"
+                                            id="draft"
+                                            checked={draft}
+                                            onChange={(e) => setDraft(e.target.checked)}
+                                            className="w-4 h-4 text-blue-600 bg-gray-100 border-gray-300 rounded focus:ring-blue-500 dark:focus:ring-blue-600 dark:ring-offset-gray-800 focus:ring-2 dark:bg-gray-700 dark:border-gray-600"
+                                        />
+                                        <label htmlFor="draft" className="ml-2 text-sm font-medium text-gray-900 dark:text-gray-300">
+                                            保存为草稿
+                                        </label>
+                                    </div>
+                                    <div className="flex-1 min-w-0">
+                                        <label className="font-medium text-gray-600 dark:text-gray-400 text-sm mb-1 block">定时发布 (可选)</label>
+                                        <input
+                                            type="datetime-local"
+                                            value={publishDate}
+                                            onChange={(e) => setPublishDate(e.target.value)}
+                                            className="border border-gray-300 dark:border-gray-600 dark:bg-gray-700 dark:text-white px-4 py-2 rounded-lg w-full focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+                                        />
+                                    </div>
+                                    <div className="flex-1 min-w-0">
+                                        <label className="font-medium text-gray-600 dark:text-gray-400 text-sm mb-1 block">过期时间 (可选)</label>
+                                        <input
+                                            type="datetime-local"
+                                            value={expiryDate}
+                                            onChange={(e) => setExpiryDate(e.target.value)}
+                                            className="border border-gray-300 dark:border-gray-600 dark:bg-gray-700 dark:text-white px-4 py-2 rounded-lg w-full focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+                                        />
+                                    </div>
+                                </div>
 
                                 <div className="w-full">
                                     <label className="font-medium text-gray-600 dark:text-gray-400 text-sm mb-1 block">内容</label>
@@ -1112,6 +1248,14 @@ function App() {
                 onEditPost={handleEditPostFromModal}
                 pageSize={pageSize}
             />
+
+            {/* 搜索引擎提交记录模态框 */}
+            <SubmissionsModal 
+                isOpen={isSubmissionsModalOpen}
+                onClose={() => setIsSubmissionsModalOpen(false)}
+                rootDirectory={rootDirectory}
+                saveDirectory={saveDirectory}
+            />
         </div>
     )
 }
@@ -1,7 +1,27 @@
 import { useState, useEffect, useCallback } from 'react';
-import { ListPosts, LoadPost, DeletePost } from "../wailsjs/go/main/App";
+import { ListPosts, SearchPosts, LoadPost, DeletePost } from "../wailsjs/go/main/App";
+import { EventsOn } from "../wailsjs/runtime/runtime";
 import { XMarkIcon, MagnifyingGlassIcon, PencilIcon, TrashIcon } from '@heroicons/react/24/outline';
 
+// 文章状态的显示名称与样式
+const STATUS_LABELS = {
+    draft: { label: '草稿', className: 'bg-gray-200 text-gray-700 dark:bg-gray-600 dark:text-gray-200' },
+    scheduled: { label: '定时', className: 'bg-yellow-100 text-yellow-800 dark:bg-yellow-800 dark:text-yellow-100' },
+    published: { label: '已发布', className: 'bg-green-100 text-green-800 dark:bg-green-800 dark:text-green-100' },
+    expired: { label: '已过期', className: 'bg-red-100 text-red-800 dark:bg-red-800 dark:text-red-100' },
+};
+
+// 文章列表的排序字段
+const SORT_OPTIONS = [
+    { value: '', label: '按修改时间' },
+    { value: 'date', label: '按发布日期' },
+    { value: 'title', label: '按标题' },
+    { value: 'weight', label: '按权重' },
+    { value: 'wordCount', label: '按字数' },
+];
+
+const FILTER_INPUT_CLASS = 'border border-gray-300 dark:border-gray-600 rounded-md bg-white dark:bg-gray-700 px-2 py-1 text-gray-900 dark:text-white focus:outline-none focus:ring-1 focus:ring-blue-500';
+
 const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDirectory, onEditPost, pageSize = 10 }) => {
     const [posts, setPosts] = useState([]);
     const [loadingPosts, setLoadingPosts] = useState(false);
@@ -9,15 +29,31 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
     const [totalPosts, setTotalPosts] = useState(0);
     const [searchTerm, setSearchTerm] = useState('');
     const [searchTimeout, setSearchTimeout] = useState(null);
+    const [statusFilter, setStatusFilter] = useState(''); // 文章状态筛选，空表示全部
+    const [filters, setFilters] = useState({ tags: '', author: '', from: '', to: '', cover: '' }); // 标签、作者、日期范围和封面筛选
+    const [sortKey, setSortKey] = useState(''); // 排序字段，空表示按修改时间
+    const [sortOrder, setSortOrder] = useState(''); // asc / desc，空表示该字段的默认方向
+
+    const updateFilter = (name, value) => setFilters((current) => ({ ...current, [name]: value }));
 
-    // 加载文章列表（支持分页和搜索）
+    // 加载文章列表（支持分页、搜索、筛选和排序）
     const loadPosts = useCallback(async (page = 1, search = '') => {
         if (!saveDirectory) return;
 
         setLoadingPosts(true);
         try {
-            // 调用后端方法获取文章列表，包含分页和搜索参数
-            const result = await ListPosts(saveDirectory, rootDirectory || '', page, pageSize, search);
+            const filter = {
+                status: statusFilter,
+                tags: filters.tags.split(/[,，]/).map((tag) => tag.trim()).filter(Boolean),
+                author: filters.author.trim(),
+                from: filters.from,
+                to: filters.to,
+                cover: filters.cover,
+            };
+            // 有搜索词时全文搜索（标题、摘要、标签和正文），按相关度排序；否则按所选字段排序
+            const result = search.trim()
+                ? await SearchPosts(saveDirectory, rootDirectory || '', search, { ...filter, page, pageSize })
+                : await ListPosts(saveDirectory, rootDirectory || '', { ...filter, sort: sortKey, order: sortOrder, page, pageSize });
             // 修复：正确解构 ListPostsResult 对象
             const { posts: postList, totalCount } = result;
 
@@ -29,7 +65,7 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
         } finally {
             setLoadingPosts(false);
         }
-    }, [saveDirectory, rootDirectory, pageSize]);
+    }, [saveDirectory, rootDirectory, pageSize, statusFilter, filters, sortKey, sortOrder]);
 
     // 处理搜索
     const handleSearch = useCallback((term) => {
@@ -55,6 +91,17 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
         }
     }, [isOpen, saveDirectory, loadPosts, searchTerm]);
 
+    // 文章或图片在应用外被修改时刷新当前页
+    useEffect(() => {
+        if (!isOpen) return;
+        const offPosts = EventsOn('posts:changed', () => loadPosts(currentPage, searchTerm));
+        const offImages = EventsOn('images:changed', () => loadPosts(currentPage, searchTerm));
+        return () => {
+            offPosts();
+            offImages();
+        };
+    }, [isOpen, loadPosts, currentPage, searchTerm]);
+
     // 页码改变时加载对应页面的文章
     const handlePageChange = (newPage) => {
         if (newPage >= 1 && newPage <= Math.ceil(totalPosts / pageSize)) {
@@ -63,19 +110,19 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
     };
 
     // 编辑文章
-    const handleEditPost = (postTitle) => {
-        onEditPost(postTitle);
+    const handleEditPost = (postId) => {
+        onEditPost(postId);
         onClose();
     };
 
     // 删除文章
-    const handleDeletePost = async (postTitle) => {
+    const handleDeletePost = async (postId, postTitle) => {
         if (!window.confirm(`确定要删除文章 "${postTitle}" 吗？此操作不可恢复。`)) {
             return;
         }
 
         try {
-            await DeletePost(postTitle, saveDirectory, imageDirectory, rootDirectory);
+            await DeletePost(postId, saveDirectory, imageDirectory, rootDirectory);
             // 重新加载当前页面
             loadPosts(currentPage, searchTerm);
         } catch (error) {
@@ -105,7 +152,8 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
                 
                 {/* 搜索框 */}
                 <div className="p-4 border-b border-gray-200 dark:border-gray-700">
-                    <div className="relative">
+                    <div className="flex gap-2">
+                    <div className="relative flex-1">
                         <div className="absolute inset-y-0 left-0 pl-3 flex items-center pointer-events-none">
                             <MagnifyingGlassIcon className="h-5 w-5 text-gray-400" />
                         </div>
@@ -113,10 +161,82 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
                             type="text"
                             value={searchTerm}
                             onChange={(e) => handleSearch(e.target.value)}
-                            placeholder="搜索文章标题..."
+                            placeholder="搜索标题、标签和正文..."
                             className="block w-full pl-10 pr-3 py-2 border border-gray-300 dark:border-gray-600 rounded-md leading-5 bg-white dark:bg-gray-700 placeholder-gray-500 dark:placeholder-gray-400 focus:outline-none focus:placeholder-gray-400 dark:focus:placeholder-gray-300 focus:ring-1 focus:ring-blue-500 focus:border-blue-500 dark:focus:ring-blue-500 dark:focus:border-blue-500 sm:text-sm text-gray-900 dark:text-white"
                         />
                     </div>
+                    <select
+                        value={statusFilter}
+                        onChange={(e) => setStatusFilter(e.target.value)}
+                        className="border border-gray-300 dark:border-gray-600 rounded-md bg-white dark:bg-gray-700 px-3 py-2 sm:text-sm text-gray-900 dark:text-white focus:outline-none focus:ring-1 focus:ring-blue-500"
+                    >
+                        <option value="">全部</option>
+                        {Object.entries(STATUS_LABELS).map(([value, { label }]) => (
+                            <option key={value} value={value}>{label}</option>
+                        ))}
+                    </select>
+                    </div>
+                    <div className="flex flex-wrap gap-2 mt-2 text-sm">
+                        <input
+                            type="text"
+                            value={filters.tags}
+                            onChange={(e) => updateFilter('tags', e.target.value)}
+                            placeholder="标签，多个用逗号分隔"
+                            className={`${FILTER_INPUT_CLASS} w-44`}
+                        />
+                        <input
+                            type="text"
+                            value={filters.author}
+                            onChange={(e) => updateFilter('author', e.target.value)}
+                            placeholder="作者"
+                            className={`${FILTER_INPUT_CLASS} w-28`}
+                        />
+                        <input
+                            type="date"
+                            value={filters.from}
+                            onChange={(e) => updateFilter('from', e.target.value)}
+                            title="开始日期"
+                            className={FILTER_INPUT_CLASS}
+                        />
+                        <span className="self-center text-gray-500 dark:text-gray-400">至</span>
+                        <input
+                            type="date"
+                            value={filters.to}
+                            onChange={(e) => updateFilter('to', e.target.value)}
+                            title="结束日期"
+                            className={FILTER_INPUT_CLASS}
+                        />
+                        <select
+                            value={filters.cover}
+                            onChange={(e) => updateFilter('cover', e.target.value)}
+                            className={FILTER_INPUT_CLASS}
+                        >
+                            <option value="">封面不限</option>
+                            <option value="with">有封面</option>
+                            <option value="without">无封面</option>
+                        </select>
+                        <select
+                            value={sortKey}
+                            onChange={(e) => setSortKey(e.target.value)}
+                            disabled={!!searchTerm.trim()}
+                            title={searchTerm.trim() ? '搜索结果按相关度排序' : '排序'}
+                            className={FILTER_INPUT_CLASS}
+                        >
+                            {SORT_OPTIONS.map(({ value, label }) => (
+                                <option key={value} value={value}>{label}</option>
+                            ))}
+                        </select>
+                        <select
+                            value={sortOrder}
+                            onChange={(e) => setSortOrder(e.target.value)}
+                            disabled={!!searchTerm.trim()}
+                            className={FILTER_INPUT_CLASS}
+                        >
+                            <option value="">默认顺序</option>
+                            <option value="asc">升序</option>
+                            <option value="desc">降序</option>
+                        </select>
+                    </div>
                 </div>
                 
                 {/* 内容区域 */}
@@ -144,17 +264,31 @@ const PostListModal = ({ isOpen, onClose, saveDirectory, imageDirectory, rootDir
                                                 <td className="px-6 py-4 whitespace-nowrap">
                                                     <div className="text-sm font-medium text-gray-900 dark:text-white truncate">
                                                         {post.title}
+                                                        {post.status && post.status !== 'published' && STATUS_LABELS[post.status] && (
+                                                            <span className={`ml-2 px-2 py-0.5 rounded text-xs ${STATUS_LABELS[post.status].className}`}>
+                                                                {STATUS_LABELS[post.status].label}
+                                                            </span>
+                                                        )}
                                                     </div>
+                                                    {post.snippet && post.snippet.length > 0 && (
+                                                        <div className="mt-1 text-xs text-gray-500 dark:text-gray-400 whitespace-normal">
+                                                            {post.snippet.map((fragment, i) => fragment.match ? (
+                                                                <mark key={i} className="bg-yellow-200 dark:bg-yellow-700 dark:text-white rounded px-0.5">{fragment.text}</mark>
+                                                            ) : (
+                                                                <span key={i}>{fragment.text}</span>
+                                                            ))}
+                                                        </div>
+                                                    )}
                                                 </td>
                                                 <td className="px-6 py-4 whitespace-nowrap text-right text-sm font-medium">
                                                     <button
-                                                        onClick={() => handleEditPost(post.title)}
+                                                        onClick={() => handleEditPost(post.id)}
                                                         className="text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300 mr-3"
                                                     >
                                                         <PencilIcon className="h-4 w-4 inline" /> 编辑
                                                     </button>
                                                     <button
-                                                        onClick={() => handleDeletePost(post.title)}
+                                                        onClick={() => handleDeletePost(post.id, post.title)}
                                                         className="text-red-500 hover:text-red-700 dark:text-red-400 dark:hover:text-red-300"
                                                     >
                                                         <TrashIcon className="h-4 w-4 inline" /> 删除
@@ -0,0 +1,206 @@
+import { useState, useEffect, useCallback } from 'react';
+import { GetSubmissions, RetrySubmissions, CheckIndexNowKey, GenerateIndexNowKey, ResubmitSite } from "../wailsjs/go/main/App";
+import { XMarkIcon, ArrowPathIcon } from '@heroicons/react/24/outline';
+
+// 提交状态的显示名称与样式
+const STATUS_LABELS = {
+    pending: { label: '等待提交', className: 'bg-yellow-100 text-yellow-800 dark:bg-yellow-800 dark:text-yellow-100' },
+    submitted: { label: '已提交', className: 'bg-green-100 text-green-800 dark:bg-green-800 dark:text-green-100' },
+    failed: { label: '失败', className: 'bg-red-100 text-red-800 dark:bg-red-800 dark:text-red-100' },
+};
+
+// 时间字段为零值时不显示
+const formatTime = (value) => {
+    if (!value) return '';
+    const date = new Date(value);
+    if (isNaN(date.getTime()) || date.getFullYear() <= 1) return '';
+    return date.toLocaleString();
+};
+
+const SubmissionsModal = ({ isOpen, onClose, rootDirectory, saveDirectory }) => {
+    const [submissions, setSubmissions] = useState([]);
+    const [keyStatus, setKeyStatus] = useState(null); // IndexNow key 文件状态
+    const [loading, setLoading] = useState(false);
+
+    // 加载搜索引擎提交记录
+    const loadSubmissions = useCallback(async () => {
+        setLoading(true);
+        try {
+            const result = await GetSubmissions();
+            setSubmissions(result || []);
+        } catch (error) {
+            console.error('Failed to load submissions:', error);
+        } finally {
+            setLoading(false);
+        }
+    }, []);
+
+    // 检查 IndexNow key 文件是否已放入 static 和 public 目录
+    const loadKeyStatus = useCallback(async () => {
+        try {
+            setKeyStatus(await CheckIndexNowKey(rootDirectory || ''));
+        } catch (error) {
+            console.error('Failed to check IndexNow key:', error);
+        }
+    }, [rootDirectory]);
+
+    useEffect(() => {
+        if (isOpen) {
+            loadSubmissions();
+            loadKeyStatus();
+        }
+    }, [isOpen, loadSubmissions, loadKeyStatus]);
+
+    // 生成 IndexNow key 文件（没有 key 时同时生成新的 key）
+    const handleGenerateKey = async () => {
+        try {
+            setKeyStatus(await GenerateIndexNowKey(rootDirectory || ''));
+        } catch (error) {
+            console.error('Failed to generate IndexNow key:', error);
+            alert('生成 key 文件失败：' + (error.message || error));
+        }
+    };
+
+    // 重新提交所有失败的地址
+    const handleRetry = async () => {
+        try {
+            await RetrySubmissions();
+            loadSubmissions();
+        } catch (error) {
+            console.error('Failed to retry submissions:', error);
+            alert('重试失败：' + (error.message || error));
+        }
+    };
+
+    // 按 sitemap（站点未构建时按文章目录）提交新增或更新的地址；force 为 true 时提交全部地址
+    const handleResubmit = async (force) => {
+        if (force && !confirm('将忽略上次的提交记录，重新提交站点的全部地址，确定继续吗？')) {
+            return;
+        }
+        try {
+            const result = await ResubmitSite(rootDirectory || '', saveDirectory || '', force);
+            const source = result.source === 'sitemap' ? 'sitemap.xml' : '文章目录';
+            alert(`已从${source}读取 ${result.total} 个地址，提交 ${result.queued} 个，${result.unchanged} 个自上次提交以来没有变化`);
+            loadSubmissions();
+        } catch (error) {
+            console.error('Failed to resubmit site:', error);
+            alert('提交失败：' + (error.message || error));
+        }
+    };
+
+    // 如果模态框未打开，不渲染任何内容
+    if (!isOpen) {
+        return null;
+    }
+
+    const hasFailed = submissions.some((entry) => entry.status === 'failed');
+
+    return (
+        <div className="fixed inset-0 bg-black bg-opacity-50 flex items-center justify-center z-50 p-4">
+            <div className="bg-white dark:bg-gray-800 rounded-lg shadow-xl w-full max-w-4xl max-h-[90vh] flex flex-col">
+                {/* 头部 */}
+                <div className="flex justify-between items-center p-4 border-b border-gray-200 dark:border-gray-700">
+                    <h3 className="text-lg font-semibold text-gray-900 dark:text-white">搜索引擎提交记录</h3>
+                    <div className="flex items-center space-x-3">
+                        <button
+                            onClick={() => handleResubmit(false)}
+                            className="text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300 text-sm"
+                            title="按 sitemap 提交新增或更新的地址"
+                        >
+                            提交更新的地址
+                        </button>
+                        <button
+                            onClick={() => handleResubmit(true)}
+                            className="text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300 text-sm"
+                            title="更换主题或迁移站点后重新提交全部地址"
+                        >
+                            全部重新提交
+                        </button>
+                        {hasFailed && (
+                            <button
+                                onClick={handleRetry}
+                                className="text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300 text-sm"
+                            >
+                                <ArrowPathIcon className="h-4 w-4 inline" /> 重试失败项
+                            </button>
+                        )}
+                        <button
+                            onClick={onClose}
+                            className="text-gray-500 hover:text-gray-700 dark:text-gray-400 dark:hover:text-gray-200"
+                        >
+                            <XMarkIcon className="h-6 w-6" />
+                        </button>
+                    </div>
+                </div>
+
+                {/* IndexNow key 文件状态 */}
+                {keyStatus && (
+                    <div className="flex justify-between items-center px-4 py-2 text-sm border-b border-gray-200 dark:border-gray-700">
+                        <div className="text-gray-700 dark:text-gray-300 break-all">
+                            {!keyStatus.key && <span className="text-yellow-600 dark:text-yellow-400">未设置 IndexNow key</span>}
+                            {keyStatus.key && !keyStatus.inStatic && <span className="text-red-500 dark:text-red-400">IndexNow key 文件不存在，不会提交 IndexNow：{keyStatus.staticPath || '未设置 static 目录'}</span>}
+                            {keyStatus.key && keyStatus.inStatic && !keyStatus.inPublic && <span className="text-yellow-600 dark:text-yellow-400">key 文件已在 static 目录，重新构建站点后才会出现在 {keyStatus.publicPath}</span>}
+                            {keyStatus.key && keyStatus.inStatic && keyStatus.inPublic && <span className="text-green-600 dark:text-green-400">IndexNow key 文件已就绪：{keyStatus.key}.txt</span>}
+                        </div>
+                        {!keyStatus.inStatic && (
+                            <button
+                                onClick={handleGenerateKey}
+                                className="ml-3 whitespace-nowrap text-blue-500 hover:text-blue-700 dark:text-blue-400 dark:hover:text-blue-300"
+                            >
+                                生成 key 文件
+                            </button>
+                        )}
+                    </div>
+                )}
+
+                {/* 内容区域 */}
+                <div className="flex-1 overflow-y-auto">
+                    {submissions.length > 0 ? (
+                        <table className="min-w-full divide-y divide-gray-200 dark:divide-gray-700">
+                            <thead className="bg-gray-50 dark:bg-gray-700">
+                                <tr>
+                                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 dark:text-gray-300 uppercase tracking-wider">地址</th>
+                                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 dark:text-gray-300 uppercase tracking-wider">渠道</th>
+                                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 dark:text-gray-300 uppercase tracking-wider">状态</th>
+                                    <th scope="col" className="px-6 py-3 text-left text-xs font-medium text-gray-500 dark:text-gray-300 uppercase tracking-wider">时间</th>
+                                </tr>
+                            </thead>
+                            <tbody className="bg-white dark:bg-gray-800 divide-y divide-gray-200 dark:divide-gray-700">
+                                {submissions.map((entry, index) => (
+                                    <tr key={index} className="hover:bg-gray-50 dark:hover:bg-gray-700">
+                                        <td className="px-6 py-3 text-sm text-gray-900 dark:text-white break-all">
+                                            {entry.url}
+                                            {entry.lastError && (
+                                                <div className="text-xs text-red-500 dark:text-red-400 mt-1">{entry.lastError}</div>
+                                            )}
+                                        </td>
+                                        <td className="px-6 py-3 whitespace-nowrap text-sm text-gray-700 dark:text-gray-300">
+                                            {entry.notifier || 'indexnow'}
+                                        </td>
+                                        <td className="px-6 py-3 whitespace-nowrap text-sm">
+                                            <span className={`px-2 py-0.5 rounded text-xs ${(STATUS_LABELS[entry.status] || STATUS_LABELS.pending).className}`}>
+                                                {(STATUS_LABELS[entry.status] || STATUS_LABELS.pending).label}
+                                            </span>
+                                            {entry.attempts > 0 && (
+                                                <span className="ml-2 text-xs text-gray-500 dark:text-gray-400">尝试 {entry.attempts} 次</span>
+                                            )}
+                                        </td>
+                                        <td className="px-6 py-3 whitespace-nowrap text-xs text-gray-500 dark:text-gray-400">
+                                            {entry.status === 'submitted' ? formatTime(entry.submittedAt) : (entry.status === 'pending' ? `下次：${formatTime(entry.nextAttempt)}` : formatTime(entry.queuedAt))}
+                                        </td>
+                                    </tr>
+                                ))}
+                            </tbody>
+                        </table>
+                    ) : (
+                        <div className="p-8 text-center text-gray-500 dark:text-gray-400">
+                            {loading ? '加载中...' : '暂无提交记录'}
+                        </div>
+                    )}
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default SubmissionsModal;
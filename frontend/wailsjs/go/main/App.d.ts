@@ -1,6 +1,11 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
+import {hugoconfig} from '../models';
+import {notify} from '../models';
+import {posts} from '../models';
+
+export function CheckIndexNowKey(arg1:string):Promise<main.IndexNowKeyStatus>;
 
 export function CheckTitleDuplicate(arg1:string,arg2:string):Promise<boolean>;
 
@@ -8,22 +13,58 @@ export function CompressImage(arg1:string,arg2:string):Promise<void>;
 
 export function DeletePost(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DismissMissedSchedules():Promise<void>;
+
+export function GenerateIndexNowKey(arg1:string):Promise<main.IndexNowKeyStatus>;
+
+export function GetActiveProfile():Promise<main.SiteProfile>;
+
+export function GetMissedSchedules():Promise<Array<main.ScheduledPost>>;
+
+export function GetSubmissions():Promise<Array<notify.Submission>>;
+
 export function Greet(arg1:string):Promise<string>;
 
-export function ListPosts(arg1:string,arg2:string,arg3:number,arg4:number,arg5:string):Promise<main.ListPostsResult>;
+export function ListPosts(arg1:string,arg2:string,arg3:posts.Query):Promise<main.ListPostsResult>;
 
 export function ListPostsSimple(arg1:string):Promise<Array<string>>;
 
+export function ListProfiles():Promise<Array<main.SiteProfile>>;
+
+export function ListScheduledPosts():Promise<Array<main.ScheduledPost>>;
+
 export function LoadImageAsBase64(arg1:string,arg2:string):Promise<string>;
 
 export function LoadPost(arg1:string,arg2:string):Promise<string>;
 
+export function LoadSiteConfig(arg1:string):Promise<hugoconfig.Config>;
+
+export function ResolveImageTarget(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.ImageTarget>;
+
+export function ResolvePostURL(arg1:string,arg2:string):Promise<string>;
+
+export function ResubmitSite(arg1:string,arg2:string,arg3:boolean):Promise<main.ResubmitResult>;
+
+export function RetrySubmissions():Promise<void>;
+
 export function SaveAndCompressImage(arg1:string,arg2:string,arg3:string):Promise<void>;
 
-export function SavePost(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:Array<string>,arg8:number,arg9:string,arg10:string,arg11:boolean):Promise<void>;
+export function SavePost(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:Array<string>,arg8:number,arg9:string,arg10:string,arg11:boolean,arg12:boolean,arg13:string,arg14:string):Promise<void>;
+
+export function SaveProfile(arg1:main.SiteProfile):Promise<void>;
+
+export function SearchPosts(arg1:string,arg2:string,arg3:string,arg4:main.SearchFilters):Promise<main.SearchPostsResult>;
 
 export function SelectDirectory():Promise<string>;
 
 export function SelectImageDirectory():Promise<string>;
 
-export function UpdatePost(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:Array<string>,arg9:number,arg10:string,arg11:string,arg12:boolean):Promise<void>;
+export function SetRedirectsOutput(arg1:boolean,arg2:string):Promise<void>;
+
+export function SwitchProfile(arg1:string):Promise<void>;
+
+export function UpdatePost(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:Array<string>,arg9:number,arg10:string,arg11:string,arg12:boolean,arg13:boolean,arg14:string,arg15:string):Promise<void>;
+
+export function WatchDirectories(arg1:string,arg2:string):Promise<void>;
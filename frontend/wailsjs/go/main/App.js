@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckIndexNowKey(arg1) {
+  return window['go']['main']['App']['CheckIndexNowKey'](arg1);
+}
+
 export function CheckTitleDuplicate(arg1, arg2) {
   return window['go']['main']['App']['CheckTitleDuplicate'](arg1, arg2);
 }
@@ -14,18 +18,50 @@ export function DeletePost(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['DeletePost'](arg1, arg2, arg3, arg4);
 }
 
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DismissMissedSchedules() {
+  return window['go']['main']['App']['DismissMissedSchedules']();
+}
+
+export function GenerateIndexNowKey(arg1) {
+  return window['go']['main']['App']['GenerateIndexNowKey'](arg1);
+}
+
+export function GetActiveProfile() {
+  return window['go']['main']['App']['GetActiveProfile']();
+}
+
+export function GetMissedSchedules() {
+  return window['go']['main']['App']['GetMissedSchedules']();
+}
+
+export function GetSubmissions() {
+  return window['go']['main']['App']['GetSubmissions']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
-export function ListPosts(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['ListPosts'](arg1, arg2, arg3, arg4, arg5);
+export function ListPosts(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListPosts'](arg1, arg2, arg3);
 }
 
 export function ListPostsSimple(arg1) {
   return window['go']['main']['App']['ListPostsSimple'](arg1);
 }
 
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function ListScheduledPosts() {
+  return window['go']['main']['App']['ListScheduledPosts']();
+}
+
 export function LoadImageAsBase64(arg1, arg2) {
   return window['go']['main']['App']['LoadImageAsBase64'](arg1, arg2);
 }
@@ -34,12 +70,40 @@ export function LoadPost(arg1, arg2) {
   return window['go']['main']['App']['LoadPost'](arg1, arg2);
 }
 
+export function LoadSiteConfig(arg1) {
+  return window['go']['main']['App']['LoadSiteConfig'](arg1);
+}
+
+export function ResolveImageTarget(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ResolveImageTarget'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function ResolvePostURL(arg1, arg2) {
+  return window['go']['main']['App']['ResolvePostURL'](arg1, arg2);
+}
+
+export function ResubmitSite(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ResubmitSite'](arg1, arg2, arg3);
+}
+
+export function RetrySubmissions() {
+  return window['go']['main']['App']['RetrySubmissions']();
+}
+
 export function SaveAndCompressImage(arg1, arg2, arg3) {
   return window['go']['main']['App']['SaveAndCompressImage'](arg1, arg2, arg3);
 }
 
-export function SavePost(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11) {
-  return window['go']['main']['App']['SavePost'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11);
+export function SavePost(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13, arg14) {
+  return window['go']['main']['App']['SavePost'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13, arg14);
+}
+
+export function SaveProfile(arg1) {
+  return window['go']['main']['App']['SaveProfile'](arg1);
+}
+
+export function SearchPosts(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SearchPosts'](arg1, arg2, arg3, arg4);
 }
 
 export function SelectDirectory() {
@@ -50,6 +114,18 @@ export function SelectImageDirectory() {
   return window['go']['main']['App']['SelectImageDirectory']();
 }
 
-export function UpdatePost(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12) {
-  return window['go']['main']['App']['UpdatePost'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12);
+export function SetRedirectsOutput(arg1, arg2) {
+  return window['go']['main']['App']['SetRedirectsOutput'](arg1, arg2);
+}
+
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
+export function UpdatePost(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13, arg14, arg15) {
+  return window['go']['main']['App']['UpdatePost'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12, arg13, arg14, arg15);
+}
+
+export function WatchDirectories(arg1, arg2) {
+  return window['go']['main']['App']['WatchDirectories'](arg1, arg2);
 }
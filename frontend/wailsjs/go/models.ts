@@ -1,14 +1,56 @@
+export namespace hugoconfig {
+	
+	export class Config {
+	    root: string;
+	    files: string[];
+	    baseURL: string;
+	    contentDir: string;
+	    staticDirs: string[];
+	    publishDir: string;
+	    defaultContentLanguage: string;
+	    permalinks: Record<string, string>;
+	    taxonomies: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = source["root"];
+	        this.files = source["files"];
+	        this.baseURL = source["baseURL"];
+	        this.contentDir = source["contentDir"];
+	        this.staticDirs = source["staticDirs"];
+	        this.publishDir = source["publishDir"];
+	        this.defaultContentLanguage = source["defaultContentLanguage"];
+	        this.permalinks = source["permalinks"];
+	        this.taxonomies = source["taxonomies"];
+	    }
+	}
+
+}
+
 export namespace main {
 	
 	export class PostInfo {
+	    id: string;
 	    title: string;
 	    coverImage: string;
-	    coverImageBase64: string;
 	    slug: string;
 	    keywords: string[];
 	    hiddenInList: boolean;
 	    date: string;
 	    lastmod: string;
+	    publishDate: string;
+	    expiryDate: string;
+	    draft: boolean;
+	    status: string;
+	    tags: string[];
+	    author: string[];
+	    weight: number;
+	    wordCount: number;
+	    coverThumbnail: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new PostInfo(source);
@@ -16,14 +58,23 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
 	        this.title = source["title"];
 	        this.coverImage = source["coverImage"];
-	        this.coverImageBase64 = source["coverImageBase64"];
 	        this.slug = source["slug"];
 	        this.keywords = source["keywords"];
 	        this.hiddenInList = source["hiddenInList"];
 	        this.date = source["date"];
 	        this.lastmod = source["lastmod"];
+	        this.publishDate = source["publishDate"];
+	        this.expiryDate = source["expiryDate"];
+	        this.draft = source["draft"];
+	        this.status = source["status"];
+	        this.tags = source["tags"];
+	        this.author = source["author"];
+	        this.weight = source["weight"];
+	        this.wordCount = source["wordCount"];
+	        this.coverThumbnail = source["coverThumbnail"];
 	    }
 	}
 	export class ListPostsResult {
@@ -58,6 +109,391 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class NotifierConfig {
+	    name: string;
+	    type: string;
+	    endpoint: string;
+	    token: string;
+	    disabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotifierConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.endpoint = source["endpoint"];
+	        this.token = source["token"];
+	        this.disabled = source["disabled"];
+	    }
+	}
+	export class SiteProfile {
+	    name: string;
+	    baseURL: string;
+	    contentDir: string;
+	    staticDir: string;
+	    imageDir: string;
+	    defaultAuthor: string;
+	    indexNowKey: string;
+	    permalinkPattern: string;
+	    postLayout: string;
+	    buildCommand: string;
+	    notifiers: NotifierConfig[];
+	    emitRedirects: boolean;
+	    redirectsDir: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SiteProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.baseURL = source["baseURL"];
+	        this.contentDir = source["contentDir"];
+	        this.staticDir = source["staticDir"];
+	        this.imageDir = source["imageDir"];
+	        this.defaultAuthor = source["defaultAuthor"];
+	        this.indexNowKey = source["indexNowKey"];
+	        this.permalinkPattern = source["permalinkPattern"];
+	        this.postLayout = source["postLayout"];
+	        this.buildCommand = source["buildCommand"];
+	        this.notifiers = this.convertValues(source["notifiers"], NotifierConfig);
+	        this.emitRedirects = source["emitRedirects"];
+	        this.redirectsDir = source["redirectsDir"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImageTarget {
+	    path: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageTarget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.url = source["url"];
+	    }
+	}
+	export class ScheduledPost {
+	    id: string;
+	    directory: string;
+	    title: string;
+	    url: string;
+	    profile: string;
+	    root: string;
+	    publishAt: any;
+	    status: string;
+	    missed: boolean;
+	    publishedAt: any;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduledPost(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.directory = source["directory"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.profile = source["profile"];
+	        this.root = source["root"];
+	        this.publishAt = source["publishAt"];
+	        this.status = source["status"];
+	        this.missed = source["missed"];
+	        this.publishedAt = source["publishedAt"];
+	        this.error = source["error"];
+	    }
+	}
+	export class IndexNowKeyStatus {
+	    key: string;
+	    staticPath: string;
+	    inStatic: boolean;
+	    publicPath: string;
+	    inPublic: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexNowKeyStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.staticPath = source["staticPath"];
+	        this.inStatic = source["inStatic"];
+	        this.publicPath = source["publicPath"];
+	        this.inPublic = source["inPublic"];
+	    }
+	}
+	export class ResubmitResult {
+	    source: string;
+	    path: string;
+	    total: number;
+	    queued: number;
+	    unchanged: number;
+	    urls: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResubmitResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.path = source["path"];
+	        this.total = source["total"];
+	        this.queued = source["queued"];
+	        this.unchanged = source["unchanged"];
+	        this.urls = source["urls"];
+	    }
+	}
+	export class SearchFilters {
+	    status: string;
+	    tags: string[];
+	    keywords: string[];
+	    author: string;
+	    from: string;
+	    to: string;
+	    cover: string;
+	    directory: string;
+	    page: number;
+	    pageSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchFilters(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.tags = source["tags"];
+	        this.keywords = source["keywords"];
+	        this.author = source["author"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.cover = source["cover"];
+	        this.directory = source["directory"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	    }
+	}
+	export class SearchPostInfo {
+	    id: string;
+	    title: string;
+	    coverImage: string;
+	    slug: string;
+	    keywords: string[];
+	    hiddenInList: boolean;
+	    date: string;
+	    lastmod: string;
+	    publishDate: string;
+	    expiryDate: string;
+	    draft: boolean;
+	    status: string;
+	    tags: string[];
+	    author: string[];
+	    weight: number;
+	    wordCount: number;
+	    coverThumbnail: string;
+	    score: number;
+	    snippet: search.Fragment[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchPostInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.coverImage = source["coverImage"];
+	        this.slug = source["slug"];
+	        this.keywords = source["keywords"];
+	        this.hiddenInList = source["hiddenInList"];
+	        this.date = source["date"];
+	        this.lastmod = source["lastmod"];
+	        this.publishDate = source["publishDate"];
+	        this.expiryDate = source["expiryDate"];
+	        this.draft = source["draft"];
+	        this.status = source["status"];
+	        this.tags = source["tags"];
+	        this.author = source["author"];
+	        this.weight = source["weight"];
+	        this.wordCount = source["wordCount"];
+	        this.coverThumbnail = source["coverThumbnail"];
+	        this.score = source["score"];
+	        this.snippet = this.convertValues(source["snippet"], search.Fragment);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchPostsResult {
+	    posts: SearchPostInfo[];
+	    totalCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchPostsResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.posts = this.convertValues(source["posts"], SearchPostInfo);
+	        this.totalCount = source["totalCount"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace notify {
+	
+	export class Submission {
+	    url: string;
+	    profile: string;
+	    notifier: string;
+	    status: string;
+	    attempts: number;
+	    statusCode: number;
+	    lastError: string;
+	    queuedAt: any;
+	    nextAttempt: any;
+	    submittedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Submission(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.profile = source["profile"];
+	        this.notifier = source["notifier"];
+	        this.status = source["status"];
+	        this.attempts = source["attempts"];
+	        this.statusCode = source["statusCode"];
+	        this.lastError = source["lastError"];
+	        this.queuedAt = source["queuedAt"];
+	        this.nextAttempt = source["nextAttempt"];
+	        this.submittedAt = source["submittedAt"];
+	    }
+	}
+
+}
+
+export namespace posts {
+	
+	export class Query {
+	    status: string;
+	    tags: string[];
+	    keywords: string[];
+	    author: string;
+	    from: string;
+	    to: string;
+	    cover: string;
+	    directory: string;
+	    search: string;
+	    sort: string;
+	    order: string;
+	    page: number;
+	    pageSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.tags = source["tags"];
+	        this.keywords = source["keywords"];
+	        this.author = source["author"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.cover = source["cover"];
+	        this.directory = source["directory"];
+	        this.search = source["search"];
+	        this.sort = source["sort"];
+	        this.order = source["order"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	    }
+	}
+
+}
+
+export namespace search {
+	
+	export class Fragment {
+	    text: string;
+	    match: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Fragment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.text = source["text"];
+	        this.match = source["match"];
+	    }
+	}
 
 }
 
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/hugoconfig"
+)
+
+// indexNowKeyPattern is the key format the IndexNow protocol accepts
+var indexNowKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9-]{8,128}$`)
+
+// IndexNowKeyStatus reports whether a site serves its IndexNow key file
+type IndexNowKeyStatus struct {
+	Key        string `json:"key"`        // 当前站点配置的 IndexNow key
+	StaticPath string `json:"staticPath"` // static 目录中的 key 文件
+	InStatic   bool   `json:"inStatic"`   // static 目录中存在且内容正确
+	PublicPath string `json:"publicPath"` // 构建输出（public）目录中的 key 文件
+	InPublic   bool   `json:"inPublic"`   // 构建输出中存在且内容正确
+}
+
+// newIndexNowKey returns a random 32 character hex key
+func newIndexNowKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hasKeyFile reports whether path holds key, as IndexNow verifies it
+func hasKeyFile(path, key string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.TrimSpace(string(data)) == key
+}
+
+// siteDirs returns the static and build output directories of the site
+// rooted at rootDirectory, or of profile's site when rootDirectory is ""
+func (a *App) siteDirs(profile SiteProfile, rootDirectory string) (staticDir, publicDir string) {
+	if rootDirectory != "" {
+		cfg, err := hugoconfig.Load(rootDirectory)
+		if err != nil {
+			return filepath.Join(rootDirectory, "static"), filepath.Join(rootDirectory, "public")
+		}
+		return cfg.StaticPath(), cfg.PublishPath()
+	}
+
+	if cfg := a.hugoConfig(); cfg != nil && profile.Name == a.settings.active().Name {
+		return cfg.StaticPath(), cfg.PublishPath()
+	}
+	if profile.StaticDir == "" {
+		return "", ""
+	}
+	return profile.StaticDir, filepath.Join(filepath.Dir(profile.StaticDir), "public")
+}
+
+// indexNowKeyStatus looks for profile's key file in the site's static and
+// build output directories
+func (a *App) indexNowKeyStatus(profile SiteProfile, rootDirectory string) IndexNowKeyStatus {
+	status := IndexNowKeyStatus{Key: profile.IndexNowKey}
+	if profile.IndexNowKey == "" {
+		return status
+	}
+
+	staticDir, publicDir := a.siteDirs(profile, rootDirectory)
+	keyFile := profile.IndexNowKey + ".txt"
+	if staticDir != "" {
+		status.StaticPath = filepath.Join(staticDir, keyFile)
+		status.InStatic = hasKeyFile(status.StaticPath, profile.IndexNowKey)
+	}
+	if publicDir != "" {
+		status.PublicPath = filepath.Join(publicDir, keyFile)
+		status.InPublic = hasKeyFile(status.PublicPath, profile.IndexNowKey)
+	}
+	return status
+}
+
+// checkIndexNowKey returns an error unless profile's key file is in the
+// site's static directory, and with built also in the build output. IndexNow
+// answers 403 for URLs of a host that does not serve the key.
+func (a *App) checkIndexNowKey(profile SiteProfile, built bool) error {
+	if profile.IndexNowKey == "" {
+		return errors.New("未设置 IndexNow key")
+	}
+	status := a.indexNowKeyStatus(profile, "")
+	switch {
+	case status.StaticPath == "":
+		return errors.New("未设置 static 目录，无法确认 IndexNow key 文件")
+	case !status.InStatic:
+		return fmt.Errorf("IndexNow key 文件不存在: %s，请先生成 key 文件", status.StaticPath)
+	case built && !status.InPublic:
+		return fmt.Errorf("构建输出中没有 IndexNow key 文件: %s", status.PublicPath)
+	}
+	return nil
+}
+
+// CheckIndexNowKey reports whether the active profile's IndexNow key file is
+// in the static directory and the built output of the site at rootDirectory
+func (a *App) CheckIndexNowKey(rootDirectory string) IndexNowKeyStatus {
+	return a.indexNowKeyStatus(a.site(), rootDirectory)
+}
+
+// GenerateIndexNowKey writes the IndexNow key file <key>.txt into the static
+// directory of the site at rootDirectory, so Hugo publishes it at the site
+// root. The active profile's key is kept if it is valid; otherwise a new key
+// is generated and saved in the profile.
+func (a *App) GenerateIndexNowKey(rootDirectory string) (IndexNowKeyStatus, error) {
+	profile := a.settings.active()
+	if profile.Name == "" {
+		return IndexNowKeyStatus{}, errors.New("请先创建站点配置")
+	}
+
+	if !indexNowKeyPattern.MatchString(profile.IndexNowKey) {
+		key, err := newIndexNowKey()
+		if err != nil {
+			return IndexNowKeyStatus{}, err
+		}
+		profile.IndexNowKey = key
+		if err := a.settings.put(profile); err != nil {
+			return IndexNowKeyStatus{}, err
+		}
+	}
+
+	staticDir, _ := a.siteDirs(profile, rootDirectory)
+	if staticDir == "" {
+		return IndexNowKeyStatus{}, errors.New("未设置 static 目录")
+	}
+	if err := os.MkdirAll(staticDir, 0755); err != nil {
+		return IndexNowKeyStatus{}, err
+	}
+	keyPath := filepath.Join(staticDir, profile.IndexNowKey+".txt")
+	if err := atomicfile.WriteFile(keyPath, []byte(profile.IndexNowKey), 0644); err != nil {
+		return IndexNowKeyStatus{}, err
+	}
+	return a.indexNowKeyStatus(profile, rootDirectory), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"hugo-publisher/pkg/posts"
+)
+
+// ImageTarget tells the frontend where to save an uploaded image and how to
+// reference it from the post
+type ImageTarget struct {
+	Path string `json:"path"` // 图片保存路径
+	URL  string `json:"url"`  // 文章中引用图片的地址
+}
+
+// ResolveImageTarget returns where an uploaded image named filename should be
+// saved with SaveAndCompressImage. With a plain file layout images go to
+// imageDirectory and are referenced under /images/uploads/. With a bundle
+// layout (a pattern ending in index.md) they are saved inside the post's
+// bundle and referenced by file name as page resources; id is the post being
+// edited, or "" for a new post whose bundle is named after slug (or title).
+func (a *App) ResolveImageTarget(id, title, slug, directory, imageDirectory, filename string) (ImageTarget, error) {
+	filename = filepath.Base(filename)
+
+	store := a.store(directory)
+	var bundleDir string
+	switch {
+	case id != "":
+		// An existing post keeps the layout it was saved with
+		postFilePath, err := store.Resolve(id)
+		if err != nil {
+			return ImageTarget{}, err
+		}
+		if store.IsBundle(postFilePath) {
+			bundleDir = filepath.Dir(postFilePath)
+		}
+	default:
+		name := posts.SafeFilename(title)
+		if slug != "" {
+			name = posts.SafeFilename(slug)
+		}
+		if postPath := store.NewPath(time.Now(), name); store.IsBundle(postPath) {
+			bundleDir = filepath.Dir(postPath)
+		}
+	}
+
+	if bundleDir != "" {
+		return ImageTarget{Path: filepath.Join(bundleDir, filename), URL: filename}, nil
+	}
+
+	if imageDirectory == "" {
+		return ImageTarget{}, fmt.Errorf("未设置图片保存目录")
+	}
+	return ImageTarget{
+		Path: filepath.Join(imageDirectory, filename),
+		URL:  "/images/uploads/" + filename,
+	}, nil
+}
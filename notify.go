@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/notify"
+	"hugo-publisher/pkg/posts"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Notifier types accepted in NotifierConfig.Type
+const (
+	notifierIndexNow = "indexnow"
+	notifierBaidu    = "baidu"
+	notifierWebhook  = "webhook"
+)
+
+// validateNotifiers checks the notifier settings of a profile
+func validateNotifiers(configs []NotifierConfig) error {
+	names := make(map[string]bool)
+	for _, config := range configs {
+		switch config.Type {
+		case notifierIndexNow, notifierBaidu:
+		case notifierWebhook:
+			if u, err := url.Parse(config.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("无效的 webhook 地址: %s", config.Endpoint)
+			}
+		default:
+			return fmt.Errorf("未知的通知类型: %s", config.Type)
+		}
+		name := notifierName(config)
+		if names[name] {
+			return fmt.Errorf("通知渠道名称重复: %s", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// notifierName returns the name submissions of a notifier are logged under
+func notifierName(config NotifierConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return config.Type
+}
+
+// profileNotifiers returns the enabled notifiers of profile. A profile with
+// no notifiers configured but an IndexNow key uses the shared IndexNow
+// endpoint, as before notifiers were configurable.
+func profileNotifiers(profile SiteProfile) []NotifierConfig {
+	if len(profile.Notifiers) == 0 {
+		if profile.IndexNowKey == "" {
+			return nil
+		}
+		return []NotifierConfig{{Type: notifierIndexNow}}
+	}
+
+	var configs []NotifierConfig
+	for _, config := range profile.Notifiers {
+		if !config.Disabled {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
+
+// newNotifier builds the notifier described by config for profile's site
+func newNotifier(profile SiteProfile, config NotifierConfig) notify.Notifier {
+	switch config.Type {
+	case notifierIndexNow:
+		return &notify.IndexNow{Endpoint: config.Endpoint, Key: profile.IndexNowKey}
+	case notifierBaidu:
+		site := ""
+		if u, err := url.Parse(profile.BaseURL); err == nil && u.Host != "" {
+			site = u.Scheme + "://" + u.Host
+		}
+		return &notify.Baidu{Endpoint: config.Endpoint, Site: site, Token: config.Token}
+	case notifierWebhook:
+		return &notify.Webhook{URL: config.Endpoint, Secret: config.Token}
+	}
+	return nil
+}
+
+// appSender resolves the notifier of a submission from the current settings,
+// so edits to a profile apply to URLs already queued
+type appSender struct {
+	app *App
+}
+
+// notifier returns the notifier entry was queued for, or nil when it has
+// since been removed or disabled
+func (s appSender) notifier(entry notify.Submission) notify.Notifier {
+	profile := s.app.profileNamed(entry.Profile)
+	for _, config := range profileNotifiers(profile) {
+		if notifierName(config) == entry.Notifier {
+			return newNotifier(profile, config)
+		}
+	}
+	return nil
+}
+
+func (s appSender) BatchSize(entry notify.Submission) int {
+	if notifier := s.notifier(entry); notifier != nil {
+		return notifier.BatchSize()
+	}
+	return 0
+}
+
+func (s appSender) Send(ctx context.Context, batch []notify.Submission) notify.Result {
+	notifier := s.notifier(batch[0])
+	if notifier == nil {
+		return notify.Result{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("notifier %q is not configured for site profile %q", batch[0].Notifier, batch[0].Profile),
+		}
+	}
+
+	urls := make([]string, len(batch))
+	for i, entry := range batch {
+		urls[i] = entry.URL
+	}
+	return notifier.Notify(ctx, urls)
+}
+
+// submitURLs queues URLs of the active site for its notifiers. They are
+// sent by the outbox in the background, retried until they get through.
+func (a *App) submitURLs(urls ...string) error {
+	_, err := a.queueURLs(a.site(), false, urls...)
+	return err
+}
+
+// notifyFailed reports that the URLs of a post change were not queued for
+// every notifier, e.g. because the IndexNow key file is missing: as a
+// warning, and to the GUI as a notify:failed event the editor shows
+func (a *App) notifyFailed(err error) {
+	a.warnf("Failed to queue search engine notifications: %v", err)
+	if a.ctx != nil {
+		wailsruntime.EventsEmit(a.ctx, "notify:failed", err.Error())
+	}
+}
+
+// postURLs returns the absolute URLs of a post at the site-relative urlPath
+// and of its aliases, without duplicates. Aliases are included because they
+// are pages too: they redirect while the post is live and go away with it.
+// A post without a URL ("") has none.
+func postURLs(profile SiteProfile, urlPath string, aliases []string) []string {
+	if urlPath == "" {
+		return nil
+	}
+
+	var urls []string
+	seen := map[string]bool{}
+	add := func(target string) {
+		if !strings.Contains(target, "://") {
+			target = profile.URL(target)
+		}
+		if target == "" || seen[target] {
+			return
+		}
+		seen[target] = true
+		urls = append(urls, target)
+	}
+
+	add(urlPath)
+	for _, alias := range aliases {
+		add(posts.AliasPath(urlPath, alias))
+	}
+	return urls
+}
+
+// requireNotifiers returns an error when profile has no notifier enabled.
+// Submissions the user asks for check it; the notifications of post changes
+// are skipped quietly instead.
+func requireNotifiers(profile SiteProfile) error {
+	if len(profileNotifiers(profile)) == 0 {
+		return fmt.Errorf("no search engine notifier is configured for site profile %q", profile.Name)
+	}
+	return nil
+}
+
+// queueURLs queues URLs of profile's site for each of its notifiers; a site
+// without notifiers queues nothing. IndexNow notifiers are skipped, with an
+// error, while the site does not serve the key file; built means the site
+// was just built, so the build output must contain it too. It returns the
+// names of the notifiers the URLs were queued for.
+func (a *App) queueURLs(profile SiteProfile, built bool, urls ...string) ([]string, error) {
+	configs := profileNotifiers(profile)
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	if profile.Host() == "" {
+		return nil, fmt.Errorf("site profile %q has search engine notifiers but no base URL", profile.Name)
+	}
+
+	var keyErr error
+	var names []string
+	for _, config := range configs {
+		if config.Type == notifierIndexNow {
+			if keyErr == nil {
+				keyErr = a.checkIndexNowKey(profile, built)
+			}
+			if keyErr != nil {
+				continue
+			}
+		}
+		names = append(names, notifierName(config))
+	}
+
+	if len(names) > 0 {
+		if err := a.outbox.Add(profile.Name, names, urls, time.Now().Add(notify.SubmitDelay)); err != nil {
+			return nil, err
+		}
+	}
+	return names, keyErr
+}
+
+// GetSubmissions returns the submission outbox, newest first: URLs still
+// waiting to be submitted, and the log of submitted and failed ones
+func (a *App) GetSubmissions() []notify.Submission {
+	return a.outbox.List()
+}
+
+// RetrySubmissions queues every failed submission again
+func (a *App) RetrySubmissions() error {
+	return a.outbox.RetryFailed()
+}
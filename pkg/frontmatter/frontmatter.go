@@ -0,0 +1,223 @@
+// Package frontmatter detects and decodes the front matter block of a Hugo
+// content file. YAML (---), TOML (+++) and JSON ({ ... }) front matter are
+// supported, mirroring the formats Hugo itself accepts.
+package frontmatter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the encoding of a front matter block
+type Format string
+
+const (
+	// FormatNone means the content has no front matter
+	FormatNone Format = ""
+	// FormatYAML is front matter delimited by "---"
+	FormatYAML Format = "yaml"
+	// FormatTOML is front matter delimited by "+++"
+	FormatTOML Format = "toml"
+	// FormatJSON is front matter written as a leading JSON object
+	FormatJSON Format = "json"
+)
+
+// ErrUnterminated is returned when a front matter block has an opening
+// delimiter but no closing one
+var ErrUnterminated = errors.New("front matter is not terminated")
+
+// Cover is the PaperMod style cover block
+type Cover struct {
+	Image        string                 `json:"image"`
+	HiddenInList *bool                  `json:"hiddenInList,omitempty"` // nil when the key is absent
+	Params       map[string]interface{} `json:"params,omitempty"`       // other keys inside cover
+}
+
+// Meta is the typed view of the front matter keys the publisher understands.
+// Every other key is kept in Params untouched.
+type Meta struct {
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Date        string                 `json:"date"`
+	LastMod     string                 `json:"lastmod"`
+	PublishDate string                 `json:"publishDate"` // publishDate, pubDate or published
+	ExpiryDate  string                 `json:"expiryDate"`  // expiryDate or unpublishDate
+	Draft       bool                   `json:"draft"`
+	Slug        string                 `json:"slug"`
+	URL         string                 `json:"url"`
+	Author      []string               `json:"author"`
+	Tags        []string               `json:"tags"`
+	Keywords    []string               `json:"keywords"`
+	Aliases     []string               `json:"aliases"`
+	Weight      int                    `json:"weight"`
+	Cover       Cover                  `json:"cover"`
+	Params      map[string]interface{} `json:"params"` // unknown keys
+}
+
+// Page is a content file split into its front matter and body
+type Page struct {
+	Format      Format                 // encoding of the front matter
+	FrontMatter string                 // raw front matter without delimiters
+	Body        string                 // everything after the front matter
+	Values      map[string]interface{} // every decoded front matter key
+	Meta        Meta                   // typed view of Values
+}
+
+// Split separates the front matter from the body without decoding it.
+// Content without front matter is returned as FormatNone with the whole
+// content as body.
+func Split(content []byte) (Format, string, string, error) {
+	text := strings.TrimPrefix(string(content), "\ufeff")
+
+	switch {
+	case isDelimiterLine(text, "---"):
+		return splitDelimited(text, "---", FormatYAML)
+	case isDelimiterLine(text, "+++"):
+		return splitDelimited(text, "+++", FormatTOML)
+	case strings.HasPrefix(text, "{"):
+		dec := json.NewDecoder(strings.NewReader(text))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return FormatJSON, "", "", fmt.Errorf("invalid JSON front matter: %v", err)
+		}
+		end := int(dec.InputOffset())
+		// Treat the rest of the closing line like a delimiter line
+		body := strings.TrimLeft(text[end:], " \t")
+		body = trimLeadingNewline(trimLeadingNewline(body))
+		return FormatJSON, text[:end], body, nil
+	}
+
+	return FormatNone, "", text, nil
+}
+
+// Parse splits and decodes the front matter of a content file
+func Parse(content []byte) (*Page, error) {
+	format, frontMatter, body, err := Split(content)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Format: format, FrontMatter: frontMatter, Body: body}
+	page.Values, err = Decode(format, frontMatter)
+	if err != nil {
+		return nil, err
+	}
+	page.Meta = metaFromValues(page.Values)
+	return page, nil
+}
+
+// Decode decodes a raw front matter block of the given format into a map
+func Decode(format Format, frontMatter string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	switch format {
+	case FormatNone:
+		return values, nil
+	case FormatYAML:
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(frontMatter), &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML front matter: %v", err)
+		}
+		if m, ok := yamlValue(&doc).(map[string]interface{}); ok {
+			values = m
+		}
+	case FormatTOML:
+		if _, err := toml.Decode(frontMatter, &values); err != nil {
+			return nil, fmt.Errorf("invalid TOML front matter: %v", err)
+		}
+	case FormatJSON:
+		if err := json.Unmarshal([]byte(frontMatter), &values); err != nil {
+			return nil, fmt.Errorf("invalid JSON front matter: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported front matter format: %s", format)
+	}
+
+	return values, nil
+}
+
+// yamlValue converts a YAML node to plain Go values. Timestamps are kept as
+// the text they were written with instead of being turned into time.Time.
+func yamlValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			m[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			list = append(list, yamlValue(item))
+		}
+		return list
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	}
+
+	if node.ShortTag() == "!!timestamp" {
+		return node.Value
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return value
+}
+
+// isDelimiterLine reports whether text starts with a line holding only delim
+func isDelimiterLine(text, delim string) bool {
+	if !strings.HasPrefix(text, delim) {
+		return false
+	}
+	line, _, _ := strings.Cut(text, "\n")
+	return strings.TrimRight(line, " \t\r") == delim
+}
+
+// splitDelimited splits a block enclosed by delimiter lines
+func splitDelimited(text, delim string, format Format) (Format, string, string, error) {
+	// Skip the opening delimiter line
+	_, rest, found := strings.Cut(text, "\n")
+	if !found {
+		return format, "", "", ErrUnterminated
+	}
+
+	offset := 0
+	for offset <= len(rest) {
+		line := rest[offset:]
+		next := len(rest)
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+			next = offset + i + 1
+		}
+
+		if strings.TrimRight(line, " \t\r") == delim {
+			return format, rest[:offset], trimLeadingNewline(rest[next:]), nil
+		}
+
+		if next == len(rest) {
+			break
+		}
+		offset = next
+	}
+
+	return format, "", "", ErrUnterminated
+}
+
+// trimLeadingNewline drops the blank line conventionally written after the
+// closing delimiter
+func trimLeadingNewline(body string) string {
+	body = strings.TrimPrefix(body, "\r\n")
+	return strings.TrimPrefix(body, "\n")
+}
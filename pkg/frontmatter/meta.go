@@ -0,0 +1,217 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metaFromValues builds the typed view of a decoded front matter map
+func metaFromValues(values map[string]interface{}) Meta {
+	meta := Meta{
+		Author:   []string{},
+		Tags:     []string{},
+		Keywords: []string{},
+		Aliases:  []string{},
+		Params:   make(map[string]interface{}),
+	}
+
+	for key, value := range values {
+		// Hugo matches front matter keys case-insensitively, so we do too
+		switch strings.ToLower(key) {
+		case "title":
+			meta.Title = ToString(value)
+		case "description":
+			meta.Description = ToString(value)
+		case "date":
+			meta.Date = ToString(value)
+		case "lastmod":
+			meta.LastMod = ToString(value)
+		case "publishdate", "pubdate", "published":
+			meta.PublishDate = ToString(value)
+		case "expirydate", "unpublishdate":
+			meta.ExpiryDate = ToString(value)
+		case "draft":
+			meta.Draft = ToBool(value)
+		case "slug":
+			meta.Slug = ToString(value)
+		case "url":
+			meta.URL = ToString(value)
+		case "author":
+			meta.Author = ToStringSlice(value)
+		case "tags":
+			meta.Tags = ToStringSlice(value)
+		case "keywords":
+			meta.Keywords = ToStringSlice(value)
+		case "aliases":
+			meta.Aliases = ToStringSlice(value)
+		case "weight":
+			meta.Weight = ToInt(value)
+		case "cover":
+			meta.Cover = coverFromValue(value)
+		default:
+			meta.Params[key] = value
+		}
+	}
+
+	return meta
+}
+
+// coverFromValue decodes the cover block, which is either a table or, in
+// some hand-written posts, just the image path
+func coverFromValue(value interface{}) Cover {
+	cover := Cover{Params: make(map[string]interface{})}
+
+	fields, ok := ToMap(value)
+	if !ok {
+		cover.Image = ToString(value)
+		return cover
+	}
+
+	for key, v := range fields {
+		switch strings.ToLower(key) {
+		case "image":
+			cover.Image = ToString(v)
+		case "hiddeninlist":
+			hidden := ToBool(v)
+			cover.HiddenInList = &hidden
+		default:
+			cover.Params[key] = v
+		}
+	}
+	return cover
+}
+
+// ToString converts a decoded front matter scalar to its string form.
+// Dates keep the precision they were written with.
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return formatTime(v)
+	case []interface{}:
+		// A single-element list is a common way to write one author
+		if len(v) > 0 {
+			return ToString(v[0])
+		}
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ToStringSlice converts a list (or a comma separated string) to strings
+func ToStringSlice(value interface{}) []string {
+	result := []string{}
+
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range v {
+			if s := strings.TrimSpace(ToString(item)); s != "" {
+				result = append(result, s)
+			}
+		}
+	case []string:
+		for _, item := range v {
+			if s := strings.TrimSpace(item); s != "" {
+				result = append(result, s)
+			}
+		}
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if s := strings.TrimSpace(item); s != "" {
+				result = append(result, s)
+			}
+		}
+	default:
+		result = append(result, ToString(v))
+	}
+
+	return result
+}
+
+// ToInt converts a decoded number (or numeric string) to int
+func ToInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+// ToBool converts a decoded boolean (or boolean string) to bool
+func ToBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return b
+	}
+	return false
+}
+
+// ToMap converts a decoded table to a string keyed map
+func ToMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = item
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// formatTime renders a decoded date value the way it was written. The TOML
+// decoder marks local dates and times with dedicated zone names.
+func formatTime(t time.Time) string {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05")
+	case "time-local":
+		return t.Format("15:04:05")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// dateLayouts are the date formats Hugo accepts in front matter
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDate parses a front matter date. Values without a zone are read in
+// local time, as Hugo does by default.
+func ParseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
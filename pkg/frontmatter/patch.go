@@ -0,0 +1,902 @@
+package frontmatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Update is a single change applied by Page.Patch. Key may be a dotted path
+// such as "cover.image" to reach into a nested table. Delete removes the key.
+type Update struct {
+	Key    string
+	Value  interface{}
+	Delete bool
+}
+
+// Set returns an Update that writes value to key
+func Set(key string, value interface{}) Update {
+	return Update{Key: key, Value: value}
+}
+
+// Delete returns an Update that removes key
+func Delete(key string) Update {
+	return Update{Key: key, Delete: true}
+}
+
+// Patch applies updates to the front matter in place. Keys that are not
+// updated are written back untouched and in their original order; for YAML
+// comments are kept as well. Content without front matter gets a new YAML
+// block.
+func (p *Page) Patch(updates []Update) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	format := p.Format
+	if format == FormatNone {
+		format = FormatYAML
+	}
+
+	var patched string
+	var err error
+	switch format {
+	case FormatYAML:
+		patched, err = patchYAML(p.FrontMatter, updates)
+	case FormatTOML:
+		patched, err = patchTOML(p.FrontMatter, updates)
+	case FormatJSON:
+		patched, err = patchJSON(p.FrontMatter, updates)
+	default:
+		err = fmt.Errorf("unsupported front matter format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	values, err := Decode(format, patched)
+	if err != nil {
+		return err
+	}
+
+	p.Format = format
+	p.FrontMatter = patched
+	p.Values = values
+	p.Meta = metaFromValues(values)
+	return nil
+}
+
+// Bytes renders the page back to a content file
+func (p *Page) Bytes() []byte {
+	var buf bytes.Buffer
+
+	frontMatter := p.FrontMatter
+	if frontMatter != "" && !strings.HasSuffix(frontMatter, "\n") {
+		frontMatter += "\n"
+	}
+
+	switch p.Format {
+	case FormatYAML:
+		buf.WriteString("---\n" + frontMatter + "---\n\n")
+	case FormatTOML:
+		buf.WriteString("+++\n" + frontMatter + "+++\n\n")
+	case FormatJSON:
+		buf.WriteString(frontMatter + "\n")
+	}
+
+	buf.WriteString(p.Body)
+	return buf.Bytes()
+}
+
+// patchYAML edits the YAML node tree so comments, styles and order survive
+func patchYAML(raw string, updates []Update) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return "", fmt.Errorf("invalid YAML front matter: %v", err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{newYAMLMapping()}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("YAML front matter is not a mapping")
+	}
+
+	for _, u := range updates {
+		path := strings.Split(u.Key, ".")
+		if u.Delete {
+			yamlDelete(root, path)
+			continue
+		}
+		if err := yamlSet(root, path, u.Value); err != nil {
+			return "", fmt.Errorf("failed to set %s: %v", u.Key, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(detectIndent(raw))
+	if err := enc.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func newYAMLMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// yamlKeyIndex finds key in a mapping node, ignoring case like Hugo does
+func yamlKeyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func yamlSet(mapping *yaml.Node, path []string, value interface{}) error {
+	i := yamlKeyIndex(mapping, path[0])
+
+	if len(path) > 1 {
+		var child *yaml.Node
+		if i >= 0 && mapping.Content[i+1].Kind == yaml.MappingNode {
+			child = mapping.Content[i+1]
+		} else {
+			child = newYAMLMapping()
+			if i >= 0 {
+				mapping.Content[i+1] = child
+			} else {
+				mapping.Content = append(mapping.Content, yamlKey(path[0]), child)
+			}
+		}
+		return yamlSet(child, path[1:], value)
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return err
+	}
+	// Dates are passed as strings; write them as plain timestamps, not quoted
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && isYAMLTimestamp(node.Value) {
+		node.Tag = "!!timestamp"
+		node.Style = 0
+	}
+
+	if i < 0 {
+		mapping.Content = append(mapping.Content, yamlKey(path[0]), node)
+		return nil
+	}
+
+	// Keep the look of the value being replaced: quoting, flow lists, comments
+	old := mapping.Content[i+1]
+	if old.Kind == node.Kind {
+		node.Style = old.Style
+		if node.Kind == yaml.SequenceNode && len(old.Content) > 0 {
+			for _, item := range node.Content {
+				if item.Kind == old.Content[0].Kind {
+					item.Style = old.Content[0].Style
+				}
+			}
+		}
+	}
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+	mapping.Content[i+1] = node
+	return nil
+}
+
+// yamlDelete removes the key at path and prunes tables left empty by it
+func yamlDelete(mapping *yaml.Node, path []string) {
+	i := yamlKeyIndex(mapping, path[0])
+	if i < 0 {
+		return
+	}
+
+	if len(path) > 1 {
+		child := mapping.Content[i+1]
+		if child.Kind != yaml.MappingNode {
+			return
+		}
+		yamlDelete(child, path[1:])
+		if len(child.Content) > 0 {
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+}
+
+// isYAMLTimestamp reports whether text resolves to a timestamp when unquoted
+func isYAMLTimestamp(text string) bool {
+	var probe yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &probe); err != nil || len(probe.Content) == 0 {
+		return false
+	}
+	return probe.Content[0].ShortTag() == "!!timestamp"
+}
+
+func yamlKey(key string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+}
+
+// detectIndent returns the indentation width used by nested YAML blocks,
+// defaulting to the four spaces SavePost writes
+func detectIndent(raw string) int {
+	for _, line := range strings.Split(raw, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			if indent < 2 {
+				return 2
+			}
+			if indent > 8 {
+				return 8
+			}
+			return indent
+		}
+	}
+	return 4
+}
+
+// tomlLine is a key/value assignment found in a TOML block
+type tomlLine struct {
+	start, end int      // line range, end exclusive
+	key        []string // the dotted key, unquoted
+	keyText    string   // the key as written
+}
+
+// patchTOML rewrites only the lines holding the updated keys. Comments and
+// every other line are kept byte for byte. A nested key such as cover.image
+// is found however the table is written: as a [cover] table, as dotted keys
+// (cover.image = ...) or as an inline table (cover = { image = ... }), which
+// is re-encoded as a whole.
+func patchTOML(raw string, updates []Update) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(raw, "\n"), "\n")
+	if raw == "" {
+		lines = nil
+	}
+
+	for _, u := range updates {
+		var err error
+		if lines, err = tomlApply(lines, u); err != nil {
+			return "", fmt.Errorf("failed to set %s: %v", u.Key, err)
+		}
+	}
+
+	patched := strings.Join(lines, "\n")
+	if patched != "" {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// tomlApply applies one update to the lines of a TOML block
+func tomlApply(lines []string, u Update) ([]string, error) {
+	path := strings.Split(u.Key, ".")
+
+	var value string
+	if !u.Delete {
+		var err error
+		if value, err = tomlValue(u.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	// The key, or an inline table holding it, in each table the path can
+	// be split at: [cover] image = ..., or cover.image = ... at the top
+	for k := len(path) - 1; k >= 0; k-- {
+		table, rest := strings.Join(path[:k], "."), path[k:]
+		start, end, found := tomlTableRange(lines, table)
+		if !found {
+			continue
+		}
+		for _, kv := range tomlAssignments(lines, start, end) {
+			switch {
+			case keyEqualFold(kv.key, rest):
+				var replacement []string
+				if !u.Delete {
+					replacement = strings.Split(kv.keyText+" = "+value, "\n")
+					if kv.end-kv.start == 1 && len(replacement) == 1 {
+						replacement[0] += tomlLineComment(lines[kv.start])
+					}
+				}
+				lines = spliceLines(lines, kv.start, kv.end, replacement)
+				if u.Delete && table != "" {
+					lines = tomlPruneTable(lines, table)
+				}
+				return lines, nil
+
+			case len(kv.key) < len(rest) && keyEqualFold(kv.key, rest[:len(kv.key)]):
+				replacement, ok, err := tomlPatchInline(lines[kv.start:kv.end], kv, rest[len(kv.key):], u)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue // Not an inline table
+				}
+				return spliceLines(lines, kv.start, kv.end, replacement), nil
+			}
+		}
+	}
+	if u.Delete {
+		return lines, nil
+	}
+
+	// A new key of a table written as dotted keys goes next to its siblings
+	for k := len(path) - 2; k >= 0; k-- {
+		table, rest := strings.Join(path[:k], "."), path[k:]
+		start, end, found := tomlTableRange(lines, table)
+		if !found {
+			continue
+		}
+		insertAt := -1
+		for _, kv := range tomlAssignments(lines, start, end) {
+			if len(kv.key) >= len(rest) && keyEqualFold(kv.key[:len(rest)-1], rest[:len(rest)-1]) {
+				insertAt = kv.end
+			}
+		}
+		if insertAt >= 0 {
+			return spliceLines(lines, insertAt, insertAt, strings.Split(tomlKey(rest)+" = "+value, "\n")), nil
+		}
+	}
+
+	table, key := strings.Join(path[:len(path)-1], "."), path[len(path)-1]
+	assignment := strings.Split(tomlKey([]string{key})+" = "+value, "\n")
+	start, end, found := tomlTableRange(lines, table)
+	if !found {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		return append(append(lines, "["+table+"]"), assignment...), nil
+	}
+
+	// Append after the last non-blank line of the table
+	insertAt := end
+	for insertAt > start && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	return spliceLines(lines, insertAt, insertAt, assignment), nil
+}
+
+// tomlValue encodes a value as the right-hand side of an assignment. Dates
+// are passed as strings and written as TOML date literals.
+func tomlValue(value interface{}) (string, error) {
+	if text, ok := value.(string); ok && isTOMLDate(text) {
+		return text, nil
+	}
+	if table, ok := value.(map[string]interface{}); ok {
+		return tomlInline(table, nil, nil, false)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", err
+	}
+	encoded, ok := strings.CutPrefix(strings.TrimSuffix(buf.String(), "\n"), "v = ")
+	if !ok {
+		return "", fmt.Errorf("%v cannot be written inline", value)
+	}
+	return encoded, nil
+}
+
+// tomlKey writes a dotted key, quoting the parts that are not bare keys
+func tomlKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		parts[i] = part
+		if part == "" || strings.IndexFunc(part, func(r rune) bool {
+			return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+		}) >= 0 {
+			parts[i] = strconv.Quote(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// keyEqualFold reports whether two keys are the same, ignoring case
+func keyEqualFold(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlAssignments returns the assignments between start and end. Values
+// may span several lines (arrays, multi-line strings), so the extent of one
+// is the shortest run of lines that decodes on its own.
+func tomlAssignments(lines []string, start, end int) []tomlLine {
+	var assignments []tomlLine
+	for i := start; i < end; i++ {
+		key, keyText, ok := tomlKeyPath(lines[i])
+		if !ok {
+			continue
+		}
+		kv := tomlLine{start: i, end: i + 1, key: key, keyText: keyText}
+		for j := i + 1; j <= end; j++ {
+			var probe map[string]interface{}
+			if _, err := toml.Decode(strings.Join(lines[i:j], "\n"), &probe); err == nil {
+				kv.end = j
+				break
+			}
+		}
+		assignments = append(assignments, kv)
+		i = kv.end - 1
+	}
+	return assignments
+}
+
+// tomlKeyPath parses the dotted key of an assignment line, such as
+// cover.image or "my key", and also returns it as written
+func tomlKeyPath(line string) ([]string, string, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '[' {
+		return nil, "", false
+	}
+
+	var path []string
+	var part strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(trimmed) {
+				i++
+				part.WriteByte(trimmed[i])
+			} else if c == quote {
+				quote = 0
+			} else {
+				part.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			path = append(path, strings.TrimSpace(part.String()))
+			part.Reset()
+		case c == '=':
+			path = append(path, strings.TrimSpace(part.String()))
+			return path, strings.TrimSpace(trimmed[:i]), true
+		case c == '#':
+			return nil, "", false
+		default:
+			part.WriteByte(c)
+		}
+	}
+	return nil, "", false
+}
+
+// tomlPatchInline applies u to the inline table assigned by kv, at path
+// inside it, and returns the rewritten assignment. It returns false when
+// the value of kv is not a table.
+func tomlPatchInline(lines []string, kv tomlLine, path []string, u Update) ([]string, bool, error) {
+	text := strings.Join(lines, "\n")
+	var values map[string]interface{}
+	meta, err := toml.Decode(text, &values)
+	if err != nil {
+		return nil, false, nil
+	}
+	var table interface{} = values
+	for _, part := range kv.key {
+		table = table.(map[string]interface{})[part]
+	}
+	inline, ok := table.(map[string]interface{})
+	if !ok {
+		return nil, false, nil
+	}
+
+	// Key order, as written
+	order := make(map[string][]string)
+	for _, key := range meta.Keys() {
+		if len(key) <= len(kv.key) {
+			continue
+		}
+		parent := strings.Join(key[len(kv.key):len(key)-1], ".")
+		order[parent] = append(order[parent], key[len(key)-1])
+	}
+
+	if u.Delete {
+		tomlDeleteIn(inline, path)
+		if len(inline) == 0 {
+			return nil, true, nil // Nothing left; drop the key like an empty table
+		}
+	} else {
+		tomlSetIn(inline, path, u.Value)
+	}
+
+	value, err := tomlInline(inline, order, nil, strings.Contains(text, "{ "))
+	if err != nil {
+		return nil, false, err
+	}
+	return []string{kv.keyText + " = " + value + tomlLineComment(lines[len(lines)-1])}, true, nil
+}
+
+// tomlSetIn sets the value at path inside a decoded table
+func tomlSetIn(table map[string]interface{}, path []string, value interface{}) {
+	key := tomlFindFold(table, path[0])
+	if len(path) == 1 {
+		table[key] = value
+		return
+	}
+	child, ok := table[key].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		table[key] = child
+	}
+	tomlSetIn(child, path[1:], value)
+}
+
+// tomlDeleteIn removes the key at path inside a decoded table and prunes
+// the tables left empty by it
+func tomlDeleteIn(table map[string]interface{}, path []string) {
+	key := tomlFindFold(table, path[0])
+	if len(path) > 1 {
+		child, ok := table[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		tomlDeleteIn(child, path[1:])
+		if len(child) > 0 {
+			return
+		}
+	}
+	delete(table, key)
+}
+
+// tomlFindFold returns the stored spelling of key, ignoring case
+func tomlFindFold(table map[string]interface{}, key string) string {
+	for k := range table {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+// tomlInline writes a table as an inline table, with the keys found in
+// order (by dotted parent path) first in that order and new ones after
+func tomlInline(table map[string]interface{}, order map[string][]string, parent []string, spaced bool) (string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range order[strings.Join(parent, ".")] {
+		if _, ok := table[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	var added []string
+	for key := range table {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	keys = append(keys, added...)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		var value string
+		var err error
+		if child, ok := table[key].(map[string]interface{}); ok {
+			value, err = tomlInline(child, order, append(parent[:len(parent):len(parent)], key), spaced)
+		} else {
+			value, err = tomlValue(table[key])
+		}
+		if err != nil {
+			return "", err
+		}
+		parts[i] = tomlKey([]string{key}) + " = " + value
+	}
+	if len(parts) == 0 {
+		return "{}", nil
+	}
+	if spaced {
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	}
+	return "{" + strings.Join(parts, ", ") + "}", nil
+}
+
+// isTOMLDate reports whether text is a valid TOML date or datetime literal
+func isTOMLDate(text string) bool {
+	var probe map[string]interface{}
+	if _, err := toml.Decode("v = "+text, &probe); err != nil {
+		return false
+	}
+	_, ok := probe["v"].(time.Time)
+	return ok
+}
+
+// tomlTableRange returns the line range holding the keys of table. The
+// top-level table ("") runs up to the first table header.
+func tomlTableRange(lines []string, table string) (int, int, bool) {
+	start := -1
+	if table == "" {
+		start = 0
+	}
+
+	for i, line := range lines {
+		header, ok := tomlHeader(line)
+		if !ok {
+			continue
+		}
+		if start >= 0 {
+			return start, i, true
+		}
+		if strings.EqualFold(header, table) {
+			start = i + 1
+		}
+	}
+
+	if start < 0 {
+		return 0, 0, false
+	}
+	return start, len(lines), true
+}
+
+// tomlHeader parses a [table] header line
+func tomlHeader(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	if i := strings.Index(trimmed, "#"); i >= 0 {
+		trimmed = strings.TrimSpace(trimmed[:i])
+	}
+	trimmed = strings.TrimPrefix(strings.TrimSuffix(trimmed, "]"), "[")
+	trimmed = strings.TrimPrefix(strings.TrimSuffix(trimmed, "]"), "[")
+	return strings.TrimSpace(trimmed), true
+}
+
+// tomlLineComment returns the trailing comment of a single-line assignment,
+// including the whitespace before it
+func tomlLineComment(line string) string {
+	for i := strings.LastIndex(line, "#"); i > 0; i = strings.LastIndex(line[:i], "#") {
+		var probe map[string]interface{}
+		if _, err := toml.Decode(line[:i], &probe); err == nil {
+			code := strings.TrimRight(line[:i], " \t")
+			return line[len(code):]
+		}
+	}
+	return ""
+}
+
+// tomlPruneTable removes the header of table when no assignment is left in it
+func tomlPruneTable(lines []string, table string) []string {
+	start, end, found := tomlTableRange(lines, table)
+	if !found {
+		return lines
+	}
+	for _, line := range lines[start:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return lines
+		}
+	}
+
+	// The blank lines setting off the last table go with it
+	start--
+	if end == len(lines) {
+		for start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+			start--
+		}
+	}
+	return spliceLines(lines, start, end, nil)
+}
+
+func spliceLines(lines []string, start, end int, replacement []string) []string {
+	result := make([]string, 0, len(lines)-(end-start)+len(replacement))
+	result = append(result, lines[:start]...)
+	result = append(result, replacement...)
+	return append(result, lines[end:]...)
+}
+
+// jsonObject is a JSON object that remembers the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func parseJSONObject(raw []byte) (*jsonObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("JSON front matter is not an object")
+	}
+
+	obj := &jsonObject{values: make(map[string]json.RawMessage)}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if _, exists := obj.values[key]; !exists {
+			obj.keys = append(obj.keys, key)
+		}
+		obj.values[key] = value
+	}
+	return obj, nil
+}
+
+// find returns the stored spelling of key, ignoring case
+func (o *jsonObject) find(key string) (string, bool) {
+	for _, k := range o.keys {
+		if strings.EqualFold(k, key) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func (o *jsonObject) set(path []string, value interface{}) error {
+	key, exists := o.find(path[0])
+	if !exists {
+		key = path[0]
+		o.keys = append(o.keys, key)
+	}
+
+	var raw []byte
+	var err error
+	if len(path) > 1 {
+		child := &jsonObject{values: make(map[string]json.RawMessage)}
+		if exists {
+			if parsed, perr := parseJSONObject(o.values[key]); perr == nil {
+				child = parsed
+			}
+		}
+		if err := child.set(path[1:], value); err != nil {
+			return err
+		}
+		raw, err = child.marshal()
+	} else {
+		raw, err = marshalJSON(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	o.values[key] = raw
+	return nil
+}
+
+func (o *jsonObject) delete(path []string) error {
+	key, exists := o.find(path[0])
+	if !exists {
+		return nil
+	}
+
+	if len(path) > 1 {
+		child, err := parseJSONObject(o.values[key])
+		if err != nil {
+			return nil
+		}
+		if err := child.delete(path[1:]); err != nil {
+			return err
+		}
+		if len(child.keys) > 0 {
+			raw, err := child.marshal()
+			if err != nil {
+				return err
+			}
+			o.values[key] = raw
+			return nil
+		}
+	}
+
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// marshal writes the object compactly with keys in their original order
+func (o *jsonObject) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, o.values[key]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func marshalJSON(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// patchJSON re-encodes the object with its original key order and, when the
+// block was indented, its original indentation
+func patchJSON(raw string, updates []Update) (string, error) {
+	obj := &jsonObject{values: make(map[string]json.RawMessage)}
+	if strings.TrimSpace(raw) != "" {
+		parsed, err := parseJSONObject([]byte(raw))
+		if err != nil {
+			return "", fmt.Errorf("invalid JSON front matter: %v", err)
+		}
+		obj = parsed
+	}
+
+	for _, u := range updates {
+		path := strings.Split(u.Key, ".")
+		var err error
+		if u.Delete {
+			err = obj.delete(path)
+		} else {
+			err = obj.set(path, u.Value)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to set %s: %v", u.Key, err)
+		}
+	}
+
+	compact, err := obj.marshal()
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(strings.TrimSpace(raw), "\n") && raw != "" {
+		return string(compact), nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact, "", jsonIndent(raw)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// jsonIndent returns the indentation of the first nested line
+func jsonIndent(raw string) string {
+	for _, line := range strings.Split(raw, "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
@@ -0,0 +1,206 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// patchCase is a front matter block, the updates to apply to it and the
+// block expected back
+type patchCase struct {
+	name    string
+	in      string
+	updates []Update
+	want    string
+}
+
+// runPatchCases patches every case and checks the result byte for byte,
+// and that it still decodes
+func runPatchCases(t *testing.T, open, close string, cases []patchCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := Parse([]byte(open + tc.in + close + "body\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := page.Patch(tc.updates); err != nil {
+				t.Fatal(err)
+			}
+			if page.FrontMatter != tc.want {
+				t.Errorf("patched front matter:\n%s\nwant:\n%s", page.FrontMatter, tc.want)
+			}
+			if page.Body != "body\n" {
+				t.Errorf("body changed to %q", page.Body)
+			}
+
+			reparsed, err := Parse(page.Bytes())
+			if err != nil {
+				t.Fatalf("patched page does not parse: %v\n%s", err, page.Bytes())
+			}
+			if !reflect.DeepEqual(reparsed.Values, page.Values) {
+				t.Errorf("reparsed values %v, want %v", reparsed.Values, page.Values)
+			}
+		})
+	}
+}
+
+func TestPatchYAML(t *testing.T) {
+	runPatchCases(t, "---\n", "---\n", []patchCase{
+		{
+			name: "keeps order, comments and unknown keys",
+			in: "# Written by hand\n" +
+				"title: Old # the title\n" +
+				"series: [notes]\n" +
+				"date: 2024-03-01\n" +
+				"params:\n" +
+				"    mermaid: true\n",
+			updates: []Update{Set("title", "New"), Set("tags", []string{"Go", "Hugo"})},
+			want: "# Written by hand\n" +
+				"title: New # the title\n" +
+				"series: [notes]\n" +
+				"date: 2024-03-01\n" +
+				"params:\n" +
+				"    mermaid: true\n" +
+				"tags:\n" +
+				"    - Go\n" +
+				"    - Hugo\n",
+		},
+		{
+			name: "nested keys",
+			in: "title: Post\n" +
+				"cover:\n" +
+				"  image: old.png\n" +
+				"  alt: A photo\n",
+			updates: []Update{Set("cover.image", "new.png"), Set("cover.hiddenInList", true)},
+			want: "title: Post\n" +
+				"cover:\n" +
+				"  image: new.png\n" +
+				"  alt: A photo\n" +
+				"  hiddenInList: true\n",
+		},
+		{
+			name:    "delete",
+			in:      "title: Post\ndraft: true # not yet\nslug: post\n",
+			updates: []Update{Delete("draft"), Delete("missing")},
+			want:    "title: Post\nslug: post\n",
+		},
+	})
+}
+
+func TestPatchTOML(t *testing.T) {
+	runPatchCases(t, "+++\n", "+++\n", []patchCase{
+		{
+			name: "keeps order, comments and unknown keys",
+			in: "# Written by hand\n" +
+				"title = \"Old\" # the title\n" +
+				"series = [\"notes\"]\n" +
+				"date = 2024-03-01\n" +
+				"\n" +
+				"[params]\n" +
+				"mermaid = true\n",
+			updates: []Update{Set("title", "New"), Set("date", "2024-04-01T10:00:00+08:00"), Set("params.math", true)},
+			want: "# Written by hand\n" +
+				"title = \"New\" # the title\n" +
+				"series = [\"notes\"]\n" +
+				"date = 2024-04-01T10:00:00+08:00\n" +
+				"\n" +
+				"[params]\n" +
+				"mermaid = true\n" +
+				"math = true\n",
+		},
+		{
+			name: "table",
+			in: "title = \"Post\"\n" +
+				"\n" +
+				"[cover]\n" +
+				"image = \"old.png\"\n" +
+				"alt = \"A photo\"\n",
+			updates: []Update{Set("cover.image", "new.png"), Set("cover.hiddenInList", true)},
+			want: "title = \"Post\"\n" +
+				"\n" +
+				"[cover]\n" +
+				"image = \"new.png\"\n" +
+				"alt = \"A photo\"\n" +
+				"hiddenInList = true\n",
+		},
+		{
+			name: "dotted keys",
+			in: "title = \"Post\"\n" +
+				"cover.image = \"old.png\" # from the shoot\n" +
+				"weight = 2\n",
+			updates: []Update{Set("cover.image", "new.png"), Set("cover.hiddenInList", true)},
+			want: "title = \"Post\"\n" +
+				"cover.image = \"new.png\" # from the shoot\n" +
+				"cover.hiddenInList = true\n" +
+				"weight = 2\n",
+		},
+		{
+			name: "inline table",
+			in: "title = \"Post\"\n" +
+				"cover = { image = \"old.png\", alt = \"A photo\" } # hero\n" +
+				"weight = 2\n",
+			updates: []Update{Set("cover.image", "new.png"), Set("cover.hiddenInList", true)},
+			want: "title = \"Post\"\n" +
+				"cover = { image = \"new.png\", alt = \"A photo\", hiddenInList = true } # hero\n" +
+				"weight = 2\n",
+		},
+		{
+			name:    "new table",
+			in:      "title = \"Post\"\n",
+			updates: []Update{Set("cover.image", "new.png")},
+			want: "title = \"Post\"\n" +
+				"\n" +
+				"[cover]\n" +
+				"image = \"new.png\"\n",
+		},
+		{
+			name: "delete",
+			in: "title = \"Post\"\n" +
+				"draft = true\n" +
+				"cover = { image = \"old.png\" }\n" +
+				"\n" +
+				"[params]\n" +
+				"mermaid = true\n",
+			updates: []Update{Delete("draft"), Delete("cover.image"), Delete("params.mermaid")},
+			want:    "title = \"Post\"\n",
+		},
+		{
+			name: "delete dotted",
+			in: "title = \"Post\"\n" +
+				"cover.image = \"old.png\"\n" +
+				"cover.hiddenInList = true\n",
+			updates: []Update{Delete("cover.image")},
+			want: "title = \"Post\"\n" +
+				"cover.hiddenInList = true\n",
+		},
+	})
+}
+
+func TestPatchJSON(t *testing.T) {
+	runPatchCases(t, "", "\n", []patchCase{
+		{
+			name: "keeps order and unknown keys",
+			in: "{\n" +
+				"  \"title\": \"Old\",\n" +
+				"  \"series\": [\"notes\"],\n" +
+				"  \"cover\": {\"image\": \"old.png\", \"alt\": \"A photo\"},\n" +
+				"  \"date\": \"2024-03-01\"\n" +
+				"}",
+			updates: []Update{Set("title", "New"), Set("cover.image", "new.png"), Set("tags", []string{"Go"}), Delete("date")},
+			want: "{\n" +
+				"  \"title\": \"New\",\n" +
+				"  \"series\": [\n" +
+				"    \"notes\"\n" +
+				"  ],\n" +
+				"  \"cover\": {\n" +
+				"    \"image\": \"new.png\",\n" +
+				"    \"alt\": \"A photo\"\n" +
+				"  },\n" +
+				"  \"tags\": [\n" +
+				"    \"Go\"\n" +
+				"  ]\n" +
+				"}",
+		},
+	})
+}
@@ -0,0 +1,248 @@
+// Package hugoconfig reads the parts of a Hugo site configuration the
+// publisher needs: base URL, content and static directories, permalinks,
+// default language and taxonomies. Both a single hugo.toml / config.toml (or
+// YAML / JSON) file in the site root and split configs under
+// config/_default/ are supported.
+package hugoconfig
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/vfs"
+)
+
+// ErrNotFound is returned when a directory holds no Hugo configuration
+var ErrNotFound = errors.New("no Hugo configuration found")
+
+// Config is the subset of a Hugo site configuration used by the publisher
+type Config struct {
+	Root                   string            `json:"root"`                   // 站点根目录
+	Files                  []string          `json:"files"`                  // 读取到的配置文件
+	BaseURL                string            `json:"baseURL"`                // 站点地址
+	ContentDir             string            `json:"contentDir"`             // 内容目录（相对于根目录）
+	StaticDirs             []string          `json:"staticDirs"`             // 静态文件目录（相对于根目录）
+	PublishDir             string            `json:"publishDir"`             // 构建输出目录（相对于根目录）
+	DefaultContentLanguage string            `json:"defaultContentLanguage"` // 默认语言
+	Permalinks             map[string]string `json:"permalinks"`             // 各 section 的链接格式
+	Taxonomies             map[string]string `json:"taxonomies"`             // 分类法：单数 -> 复数
+}
+
+// configNames are the base names Hugo accepts for the main config file, in
+// the order Hugo looks for them
+var configNames = []string{"hugo", "config"}
+
+// configExts maps config file extensions to their format
+var configExts = map[string]frontmatter.Format{
+	".toml": frontmatter.FormatTOML,
+	".yaml": frontmatter.FormatYAML,
+	".yml":  frontmatter.FormatYAML,
+	".json": frontmatter.FormatJSON,
+}
+
+// Load reads the Hugo configuration of the site rooted at root
+func Load(root string) (*Config, error) {
+	return LoadFS(vfs.Dir(root), root)
+}
+
+// LoadFS reads the Hugo configuration of the site whose files are fsys;
+// root is the directory fsys stands for, which Config paths are joined to
+func LoadFS(fsys fs.FS, root string) (*Config, error) {
+	values := make(map[string]interface{})
+	var files []string
+
+	// Split configs in config/_default/ fill in whatever the root file leaves out
+	const defaultDir = "config/_default"
+	entries, err := fs.ReadDir(fsys, defaultDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || configExts[ext] == frontmatter.FormatNone {
+			continue
+		}
+
+		name := path.Join(defaultDir, entry.Name())
+		fileValues, err := readFile(fsys, root, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// hugo.toml / config.toml hold root keys; any other file name is
+		// the key its content lives under (params.toml, permalinks.toml, ...)
+		key := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if isConfigName(key) {
+			merge(values, fileValues)
+		} else {
+			merge(values, map[string]interface{}{key: fileValues})
+		}
+		files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+	}
+
+	if name, ok := findRootConfig(fsys); ok {
+		fileValues, err := readFile(fsys, root, name)
+		if err != nil {
+			return nil, err
+		}
+		// The root file wins over the config directory
+		merge(fileValues, values)
+		values = fileValues
+		files = append([]string{filepath.Join(root, name)}, files...)
+	}
+
+	if len(files) == 0 {
+		return nil, ErrNotFound
+	}
+
+	cfg := fromValues(values)
+	cfg.Root = root
+	cfg.Files = files
+	return cfg, nil
+}
+
+// ContentPath returns the absolute content directory
+func (c *Config) ContentPath() string {
+	return filepath.Join(c.Root, c.ContentDir)
+}
+
+// StaticPath returns the absolute path of the first static directory
+func (c *Config) StaticPath() string {
+	return filepath.Join(c.Root, c.StaticDirs[0])
+}
+
+// PublishPath returns the absolute directory Hugo builds the site into
+func (c *Config) PublishPath() string {
+	return filepath.Join(c.Root, c.PublishDir)
+}
+
+// Section returns the section a posts directory belongs to, i.e. its first
+// path element below the content directory ("" when outside of it)
+func (c *Config) Section(directory string) string {
+	rel, err := filepath.Rel(c.ContentPath(), directory)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return ""
+	}
+	section, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return section
+}
+
+func isConfigName(name string) bool {
+	for _, configName := range configNames {
+		if name == configName {
+			return true
+		}
+	}
+	return false
+}
+
+// findRootConfig returns the first hugo.* or config.* file in the site root
+func findRootConfig(fsys fs.FS) (string, bool) {
+	for _, name := range configNames {
+		for _, ext := range []string{".toml", ".yaml", ".yml", ".json"} {
+			if info, err := fs.Stat(fsys, name+ext); err == nil && !info.IsDir() {
+				return name + ext, true
+			}
+		}
+	}
+	return "", false
+}
+
+// readFile decodes a config file with lower-cased keys, since Hugo config
+// keys are case-insensitive
+func readFile(fsys fs.FS, root, name string) (map[string]interface{}, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := frontmatter.Decode(configExts[path.Ext(name)], string(data))
+	if err != nil {
+		return nil, errors.New(filepath.Join(root, filepath.FromSlash(name)) + ": " + err.Error())
+	}
+	return lowerKeys(values), nil
+}
+
+func lowerKeys(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if nested, ok := frontmatter.ToMap(value); ok {
+			value = lowerKeys(nested)
+		}
+		result[strings.ToLower(key)] = value
+	}
+	return result
+}
+
+// merge copies keys of src missing from dst into dst, recursing into tables
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		dstTable, dstOK := existing.(map[string]interface{})
+		srcTable, srcOK := value.(map[string]interface{})
+		if dstOK && srcOK {
+			merge(dstTable, srcTable)
+		}
+	}
+}
+
+// fromValues extracts the Config fields, applying Hugo's defaults
+func fromValues(values map[string]interface{}) *Config {
+	cfg := &Config{
+		BaseURL:                frontmatter.ToString(values["baseurl"]),
+		ContentDir:             frontmatter.ToString(values["contentdir"]),
+		StaticDirs:             frontmatter.ToStringSlice(values["staticdir"]),
+		PublishDir:             frontmatter.ToString(values["publishdir"]),
+		DefaultContentLanguage: frontmatter.ToString(values["defaultcontentlanguage"]),
+		Permalinks:             make(map[string]string),
+		Taxonomies:             make(map[string]string),
+	}
+
+	if cfg.ContentDir == "" {
+		cfg.ContentDir = "content"
+	}
+	if len(cfg.StaticDirs) == 0 {
+		cfg.StaticDirs = []string{"static"}
+	}
+	if cfg.PublishDir == "" {
+		cfg.PublishDir = "public"
+	}
+	if cfg.DefaultContentLanguage == "" {
+		cfg.DefaultContentLanguage = "en"
+	}
+
+	if permalinks, ok := frontmatter.ToMap(values["permalinks"]); ok {
+		// Since Hugo 0.119 patterns may be grouped by page kind; regular
+		// pages are what the publisher writes
+		if page, ok := frontmatter.ToMap(permalinks["page"]); ok {
+			permalinks = page
+		}
+		for section, pattern := range permalinks {
+			if s, ok := pattern.(string); ok {
+				cfg.Permalinks[section] = s
+			}
+		}
+	}
+
+	if taxonomies, ok := frontmatter.ToMap(values["taxonomies"]); ok {
+		for singular, plural := range taxonomies {
+			cfg.Taxonomies[singular] = frontmatter.ToString(plural)
+		}
+	} else {
+		cfg.Taxonomies["tag"] = "tags"
+		cfg.Taxonomies["category"] = "categories"
+	}
+
+	return cfg
+}
@@ -0,0 +1,151 @@
+package hugoconfig
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"hugo-publisher/pkg/vfs"
+)
+
+// memorySite returns an in-memory site holding files, keyed by slash path
+func memorySite(t *testing.T, files map[string]string) *vfs.Memory {
+	t.Helper()
+	fsys := vfs.NewMemory()
+	for name, content := range files {
+		if err := fsys.MkdirAll(path.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fsys
+}
+
+func TestLoadFS(t *testing.T) {
+	root := filepath.FromSlash("/site")
+	defaults := func(cfg Config) Config {
+		cfg.Root = root
+		for i, file := range cfg.Files {
+			cfg.Files[i] = filepath.Join(root, filepath.FromSlash(file))
+		}
+		if cfg.ContentDir == "" {
+			cfg.ContentDir = "content"
+		}
+		if cfg.StaticDirs == nil {
+			cfg.StaticDirs = []string{"static"}
+		}
+		if cfg.PublishDir == "" {
+			cfg.PublishDir = "public"
+		}
+		if cfg.DefaultContentLanguage == "" {
+			cfg.DefaultContentLanguage = "en"
+		}
+		if cfg.Permalinks == nil {
+			cfg.Permalinks = map[string]string{}
+		}
+		if cfg.Taxonomies == nil {
+			cfg.Taxonomies = map[string]string{"tag": "tags", "category": "categories"}
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Config
+	}{
+		{
+			name: "root file",
+			files: map[string]string{
+				"hugo.toml": "baseURL = 'https://example.com/'\ncontentDir = 'src'\n\n[permalinks]\nposts = '/:year/:slug/'\n",
+			},
+			want: Config{
+				Files:      []string{"hugo.toml"},
+				BaseURL:    "https://example.com/",
+				ContentDir: "src",
+				Permalinks: map[string]string{"posts": "/:year/:slug/"},
+			},
+		},
+		{
+			name: "hugo.toml before config.yaml",
+			files: map[string]string{
+				"hugo.toml":   "baseURL = 'https://hugo.example.com/'\n",
+				"config.yaml": "baseURL: https://config.example.com/\n",
+			},
+			want: Config{
+				Files:   []string{"hugo.toml"},
+				BaseURL: "https://hugo.example.com/",
+			},
+		},
+		{
+			name: "config directory",
+			files: map[string]string{
+				"config/_default/hugo.toml":       "baseURL = 'https://example.com/'\ndefaultContentLanguage = 'zh'\n",
+				"config/_default/permalinks.yaml": "posts: /blog/:slug/\n",
+				"config/_default/taxonomies.json": `{"tag": "tags", "series": "series"}`,
+				"config/_default/notes.txt":       "not a config file",
+			},
+			want: Config{
+				Files: []string{
+					"config/_default/hugo.toml",
+					"config/_default/permalinks.yaml",
+					"config/_default/taxonomies.json",
+				},
+				BaseURL:                "https://example.com/",
+				DefaultContentLanguage: "zh",
+				Permalinks:             map[string]string{"posts": "/blog/:slug/"},
+				Taxonomies:             map[string]string{"tag": "tags", "series": "series"},
+			},
+		},
+		{
+			name: "root file wins over config directory",
+			files: map[string]string{
+				"hugo.toml":                       "baseURL = 'https://root.example.com/'\n\n[permalinks]\nposts = '/:year/:slug/'\n",
+				"config/_default/hugo.yaml":       "baseURL: https://dir.example.com/\npublishDir: dist\n",
+				"config/_default/permalinks.json": `{"posts": "/blog/:slug/", "docs": "/docs/:slug/"}`,
+			},
+			want: Config{
+				Files: []string{
+					"hugo.toml",
+					"config/_default/hugo.yaml",
+					"config/_default/permalinks.json",
+				},
+				BaseURL:    "https://root.example.com/",
+				PublishDir: "dist",
+				Permalinks: map[string]string{"posts": "/:year/:slug/", "docs": "/docs/:slug/"},
+			},
+		},
+		{
+			name: "permalinks by page kind",
+			files: map[string]string{
+				"hugo.json": `{"permalinks": {"page": {"posts": "/:slug/"}, "section": {"posts": "/all/"}}}`,
+			},
+			want: Config{
+				Files:      []string{"hugo.json"},
+				Permalinks: map[string]string{"posts": "/:slug/"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadFS(memorySite(t, tt.files), root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := defaults(tt.want); !reflect.DeepEqual(*cfg, want) {
+				t.Errorf("LoadFS() = %+v\nwant %+v", *cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadFSNotFound(t *testing.T) {
+	fsys := memorySite(t, map[string]string{"content/post.md": "---\ntitle: Post\n---\n"})
+	if _, err := LoadFS(fsys, "/site"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadFS() of a site without config = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package images compresses the images uploaded for posts: they are scaled
+// down to fit MaxSize and re-encoded as JPEG. Thumbnails serves small copies
+// of covers to the post list.
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+
+	"github.com/disintegration/imaging"
+)
+
+// Compression settings
+const (
+	MaxSize = 1920 // longest side in pixels
+	Quality = 85   // JPEG quality
+)
+
+// Compress compresses the image at srcPath into dstPath
+func Compress(srcPath, dstPath string) error {
+	src, err := imaging.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	return save(src, dstPath)
+}
+
+// CompressData compresses an image held in memory, e.g. an upload, into
+// dstPath
+func CompressData(data []byte, dstPath string) error {
+	src, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return save(src, dstPath)
+}
+
+// save resizes src and writes it as JPEG, creating dstPath's directory
+func save(src image.Image, dstPath string) error {
+	// Resize the image to fit within MaxSize on the longest side
+	src = imaging.Fit(src, MaxSize, MaxSize, imaging.Lanczos)
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(file, src, &jpeg.Options{Quality: Quality}); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
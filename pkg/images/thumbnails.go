@@ -0,0 +1,201 @@
+package images
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hugo-publisher/pkg/atomicfile"
+
+	"github.com/disintegration/imaging"
+)
+
+// Thumbnail URLs and cache limits
+const (
+	ThumbnailPrefix  = "/thumb/"           // URL path Thumbnails serves under
+	ThumbnailWidth   = 240                 // width when a thumbnail URL gives none
+	ThumbnailSources = 2048                // images registered at once; the least recently used are dropped
+	ThumbnailMaxAge  = 30 * 24 * time.Hour // cached thumbnails unused for longer are removed by Prune
+)
+
+// Thumbnails serves scaled-down copies of local images over HTTP at
+// /thumb/<hash>?w=<width>, so a list of posts can show its covers without
+// shipping whole image files. Only the last ThumbnailSources images
+// registered with URL are served. The hash changes with the image's size
+// and modification time, so responses are cached for good and an edited
+// image gets a new URL.
+type Thumbnails struct {
+	CacheDir string // directory keeping generated thumbnails; "" keeps none
+
+	mu      sync.Mutex
+	sources map[string]*list.Element // hash -> element of recent
+	recent  *list.List               // thumbnailSource values, most recently used first
+}
+
+// thumbnailSource is an image registered with URL
+type thumbnailSource struct {
+	hash, path string
+}
+
+// NewThumbnails returns a thumbnail server caching in cacheDir
+func NewThumbnails(cacheDir string) *Thumbnails {
+	return &Thumbnails{CacheDir: cacheDir, sources: make(map[string]*list.Element), recent: list.New()}
+}
+
+// URL registers the image at srcPath and returns the URL of its thumbnail,
+// width pixels wide or ThumbnailWidth when width is 0
+func (t *Thumbnails) URL(srcPath string, width int) (string, error) {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", srcPath)
+	}
+	if width <= 0 {
+		width = ThumbnailWidth
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", srcPath, info.Size(), info.ModTime().UnixNano())))
+	hash := hex.EncodeToString(sum[:12])
+
+	t.mu.Lock()
+	if elem, ok := t.sources[hash]; ok {
+		t.recent.MoveToFront(elem)
+	} else {
+		t.sources[hash] = t.recent.PushFront(thumbnailSource{hash: hash, path: srcPath})
+		if t.recent.Len() > ThumbnailSources {
+			oldest := t.recent.Remove(t.recent.Back()).(thumbnailSource)
+			delete(t.sources, oldest.hash)
+		}
+	}
+	t.mu.Unlock()
+	return ThumbnailPrefix + hash + "?w=" + strconv.Itoa(width), nil
+}
+
+// ServeHTTP serves a thumbnail registered with URL. The w query parameter
+// sets its width, up to MaxSize; smaller images are not scaled up.
+func (t *Thumbnails) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hash, ok := strings.CutPrefix(r.URL.Path, ThumbnailPrefix)
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+	var srcPath string
+	t.mu.Lock()
+	elem, ok := t.sources[hash]
+	if ok {
+		t.recent.MoveToFront(elem)
+		srcPath = elem.Value.(thumbnailSource).path
+	}
+	t.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	width := ThumbnailWidth
+	if value := r.URL.Query().Get("w"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid width", http.StatusBadRequest)
+			return
+		}
+		width = min(n, MaxSize)
+	}
+
+	data, err := t.thumbnail(hash, srcPath, width)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "max-age=31536000, immutable")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// thumbnail returns the JPEG thumbnail of srcPath, from the cache when it
+// was generated before. Failing to cache it is not an error. A cached file
+// is touched when used, so Prune keeps it.
+func (t *Thumbnails) thumbnail(hash, srcPath string, width int) ([]byte, error) {
+	var cachePath string
+	if t.CacheDir != "" {
+		cachePath = filepath.Join(t.CacheDir, hash+"-"+strconv.Itoa(width)+".jpg")
+		if data, err := os.ReadFile(cachePath); err == nil {
+			now := time.Now()
+			os.Chtimes(cachePath, now, now)
+			return data, nil
+		}
+	}
+
+	src, err := imaging.Open(srcPath, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, err
+	}
+	if src.Bounds().Dx() > width {
+		src = imaging.Resize(src, width, 0, imaging.Lanczos)
+	}
+	// JPEG has no transparency; show it as white rather than black
+	bounds := src.Bounds()
+	flat := imaging.Overlay(imaging.New(bounds.Dx(), bounds.Dy(), color.White), src, image.Pt(0, 0), 1)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: Quality}); err != nil {
+		return nil, err
+	}
+	if cachePath != "" {
+		if err := os.MkdirAll(t.CacheDir, 0755); err == nil {
+			atomicfile.WriteFile(cachePath, buf.Bytes(), 0644)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Prune removes the cached thumbnails not used for maxAge. Thumbnails of
+// edited or deleted images are never asked for again, so without pruning
+// the cache only grows.
+func (t *Thumbnails) Prune(maxAge time.Duration) error {
+	if t.CacheDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(t.CacheDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jpg" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(t.CacheDir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BaiduEndpoint is Baidu's link submission (普通收录) API
+const BaiduEndpoint = "http://data.zz.baidu.com/urls"
+
+// baiduBatchSize is the most URLs Baidu accepts per request
+const baiduBatchSize = 2000
+
+// baiduZone is the time zone Baidu resets the daily quota in
+var baiduZone = time.FixedZone("CST", 8*60*60)
+
+// baiduResponse is the JSON body of a Baidu submission response
+type baiduResponse struct {
+	Success     int      `json:"success"`
+	Remain      int      `json:"remain"`
+	NotSameSite []string `json:"not_same_site"`
+	NotValid    []string `json:"not_valid"`
+	Error       int      `json:"error"`
+	Message     string   `json:"message"`
+}
+
+// Baidu submits URLs to Baidu's link submission API
+type Baidu struct {
+	Endpoint string // "" is BaiduEndpoint
+	Site     string // the site as verified in Baidu Search Resource Platform, e.g. https://www.example.com
+	Token    string
+	Client   *http.Client
+}
+
+// BatchSize implements Notifier
+func (n *Baidu) BatchSize() int {
+	return baiduBatchSize
+}
+
+// Notify implements Notifier
+func (n *Baidu) Notify(ctx context.Context, urls []string) Result {
+	if len(urls) == 0 {
+		return Result{}
+	}
+	if n.Site == "" || n.Token == "" {
+		return Result{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("Baidu site and token are required")}
+	}
+
+	endpoint := n.Endpoint
+	if endpoint == "" {
+		endpoint = BaiduEndpoint
+	}
+	query := url.Values{"site": {n.Site}, "token": {n.Token}}
+	body := []byte(strings.Join(urls, "\n"))
+
+	result, data := post(ctx, n.Client, "Baidu", endpoint+"?"+query.Encode(), "text/plain", body, nil)
+
+	var resp baiduResponse
+	if json.Unmarshal(data, &resp) != nil {
+		return result
+	}
+	if resp.Message == "over quota" {
+		// The daily quota is used up; try again after it resets at midnight
+		now := time.Now().In(baiduZone)
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, baiduZone)
+		result.Temporary = true
+		result.RetryAfter = midnight.Sub(now)
+	}
+	if result.Err == nil && resp.Success == 0 && len(resp.NotSameSite)+len(resp.NotValid) > 0 {
+		result.Err = fmt.Errorf("Baidu rejected every URL (not same site: %d, not valid: %d)", len(resp.NotSameSite), len(resp.NotValid))
+	}
+	return result
+}
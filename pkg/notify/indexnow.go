@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IndexNowEndpoints are the known IndexNow endpoints by name. The shared
+// endpoint forwards submissions to every participating search engine; the
+// others submit to one engine directly.
+var IndexNowEndpoints = map[string]string{
+	"indexnow": "https://api.indexnow.org/indexnow",
+	"bing":     "https://www.bing.com/indexnow",
+	"yandex":   "https://yandex.com/indexnow",
+	"seznam":   "https://search.seznam.cz/indexnow",
+}
+
+// indexNowBatchSize is the most URLs the API accepts per request
+const indexNowBatchSize = 10000
+
+// IndexNowRequest represents the request structure for IndexNow API
+type IndexNowRequest struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation"`
+	URLList     []string `json:"urlList"`
+}
+
+// IndexNow submits URLs to an IndexNow endpoint
+type IndexNow struct {
+	Endpoint    string // endpoint URL or a name from IndexNowEndpoints; "" is the shared endpoint
+	Key         string
+	KeyLocation string // URL of the key file; "" means <scheme>://<host>/<key>.txt
+	Client      *http.Client
+}
+
+// BatchSize implements Notifier
+func (n *IndexNow) BatchSize() int {
+	return indexNowBatchSize
+}
+
+// Notify implements Notifier
+func (n *IndexNow) Notify(ctx context.Context, urls []string) Result {
+	if len(urls) == 0 {
+		return Result{}
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil || u.Host == "" {
+		return Result{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid URL: %s", urls[0])}
+	}
+	if n.Key == "" {
+		return Result{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("IndexNow key is not set")}
+	}
+
+	keyLocation := n.KeyLocation
+	if keyLocation == "" {
+		keyLocation = fmt.Sprintf("%s://%s/%s.txt", u.Scheme, u.Host, n.Key)
+	}
+	body, err := json.Marshal(IndexNowRequest{
+		Host:        u.Host,
+		Key:         n.Key,
+		KeyLocation: keyLocation,
+		URLList:     urls,
+	})
+	if err != nil {
+		return localError("failed to marshal IndexNow request: %v", err)
+	}
+
+	result, _ := post(ctx, n.Client, "IndexNow", n.endpoint(), "application/json; charset=utf-8", body, nil)
+	return result
+}
+
+func (n *IndexNow) endpoint() string {
+	if n.Endpoint == "" {
+		return IndexNowEndpoints["indexnow"]
+	}
+	if endpoint, ok := IndexNowEndpoints[n.Endpoint]; ok {
+		return endpoint
+	}
+	return n.Endpoint
+}
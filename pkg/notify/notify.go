@@ -0,0 +1,97 @@
+// Package notify tells search engines (and anything else listening) that
+// pages of a site were added, changed or removed. An Outbox queues the URLs
+// and retries them until they get through.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout bounds a request when a notifier has no Client of its own
+const DefaultTimeout = 30 * time.Second
+
+// Notifier submits changed URLs to one service
+type Notifier interface {
+	// Notify submits urls, which all belong to the same host
+	Notify(ctx context.Context, urls []string) Result
+	// BatchSize is the largest number of URLs one Notify call accepts
+	BatchSize() int
+}
+
+// Result is the outcome of a Notify call
+type Result struct {
+	StatusCode int           // HTTP status, 0 when no response was received
+	RetryAfter time.Duration // requested delay before retrying, 0 when not given
+	Temporary  bool          // the service reported a temporary failure (e.g. quota)
+	Permanent  bool          // the request could not be built or sent as configured, so retrying cannot help
+	Err        error
+}
+
+// Retryable reports whether a failed submission should be tried again:
+// network errors, rate limiting, server errors and temporary failures.
+// Local errors such as a malformed endpoint never are.
+func (r Result) Retryable() bool {
+	if r.Permanent {
+		return false
+	}
+	return r.Temporary || r.StatusCode == 0 || r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500
+}
+
+// localError is the Result of a request that failed before anything was
+// sent
+func localError(format string, args ...interface{}) Result {
+	return Result{Permanent: true, Err: fmt.Errorf(format, args...)}
+}
+
+// Host returns the host of an absolute URL
+func Host(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// post sends body to endpoint and returns the response body. Any non-2xx
+// response is turned into an error naming service.
+func post(ctx context.Context, client *http.Client, service, endpoint, contentType string, body []byte, header http.Header) (Result, []byte) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return localError("failed to create %s request: %v", service, err), nil
+	}
+	if (req.URL.Scheme != "http" && req.URL.Scheme != "https") || req.URL.Host == "" {
+		return localError("invalid %s endpoint: %s", service, endpoint), nil
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{Err: fmt.Errorf("failed to send %s request: %v", service, err)}, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return result, data
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		result.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	result.Err = fmt.Errorf("%s submission failed with status %d: %s", service, resp.StatusCode, bytes.TrimSpace(data))
+	return result, data
+}
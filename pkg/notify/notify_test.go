@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a test server that records the requests it gets and answers
+// each with the next of its responses, repeating the last one
+type recorder struct {
+	mu        sync.Mutex
+	bodies    []string
+	queries   []string
+	headers   []http.Header
+	responses []response
+}
+
+// response is an answer of a recorder
+type response struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+func newRecorder(t *testing.T, responses ...response) (*recorder, *httptest.Server) {
+	t.Helper()
+	rec := &recorder{responses: responses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.bodies = append(rec.bodies, string(body))
+		rec.queries = append(rec.queries, r.URL.RawQuery)
+		rec.headers = append(rec.headers, r.Header.Clone())
+		resp := response{status: http.StatusOK}
+		if n := len(rec.responses); n > 0 {
+			resp = rec.responses[min(len(rec.bodies), n)-1]
+		}
+		rec.mu.Unlock()
+
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+		w.WriteHeader(resp.status)
+		io.WriteString(w, resp.body)
+	}))
+	t.Cleanup(server.Close)
+	return rec, server
+}
+
+// requests returns the number of requests received
+func (rec *recorder) requests() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.bodies)
+}
+
+// testURLs returns n URLs of example.com
+func testURLs(n int) []string {
+	urls := make([]string, n)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/posts/%d/", i)
+	}
+	return urls
+}
+
+func TestIndexNowNotify(t *testing.T) {
+	rec, server := newRecorder(t)
+	n := &IndexNow{Endpoint: server.URL, Key: "abc123", Client: server.Client()}
+
+	urls := testURLs(3)
+	if result := n.Notify(context.Background(), urls); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	var req IndexNowRequest
+	if err := json.Unmarshal([]byte(rec.bodies[0]), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Host != "example.com" || req.Key != "abc123" || req.KeyLocation != "https://example.com/abc123.txt" {
+		t.Errorf("request = %+v", req)
+	}
+	if strings.Join(req.URLList, " ") != strings.Join(urls, " ") {
+		t.Errorf("urlList = %v, want %v", req.URLList, urls)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	rec, server := newRecorder(t)
+	n := &Webhook{URL: server.URL, Secret: "s3cret", Client: server.Client()}
+	if result := n.Notify(context.Background(), testURLs(2)); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(rec.bodies[0]))
+	if got, want := rec.headers[0].Get(SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("%s = %s, want %s", SignatureHeader, got, want)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal([]byte(rec.bodies[0]), &payload); err != nil || payload.Host != "example.com" || len(payload.URLs) != 2 {
+		t.Errorf("payload = %+v (%v)", payload, err)
+	}
+}
+
+func TestNotifyRetryableStatus(t *testing.T) {
+	tests := []struct {
+		resp       response
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{response{status: http.StatusTooManyRequests, retryAfter: "120"}, true, 2 * time.Minute},
+		{response{status: http.StatusServiceUnavailable, retryAfter: "30"}, true, 30 * time.Second},
+		{response{status: http.StatusInternalServerError}, true, 0},
+		{response{status: http.StatusForbidden, body: "key not valid"}, false, 0},
+		{response{status: http.StatusUnprocessableEntity}, false, 0},
+	}
+	for _, tt := range tests {
+		_, server := newRecorder(t, tt.resp)
+		n := &IndexNow{Endpoint: server.URL, Key: "abc123", Client: server.Client()}
+		result := n.Notify(context.Background(), testURLs(1))
+		if result.Err == nil {
+			t.Errorf("status %d: no error", tt.resp.status)
+			continue
+		}
+		if result.StatusCode != tt.resp.status || result.Retryable() != tt.retryable || result.RetryAfter != tt.retryAfter {
+			t.Errorf("status %d: got status %d, retryable %t, retry after %v; want retryable %t, retry after %v",
+				tt.resp.status, result.StatusCode, result.Retryable(), result.RetryAfter, tt.retryable, tt.retryAfter)
+		}
+	}
+}
+
+func TestNotifyLocalErrorsAreNotRetried(t *testing.T) {
+	for _, endpoint := range []string{"://broken", "example.com/indexnow", "ftp://example.com/indexnow"} {
+		n := &IndexNow{Endpoint: endpoint, Key: "abc123"}
+		result := n.Notify(context.Background(), testURLs(1))
+		if result.Err == nil || result.Retryable() {
+			t.Errorf("endpoint %q: err %v, retryable %t; want a permanent error", endpoint, result.Err, result.Retryable())
+		}
+	}
+
+	// A connection failure is worth retrying
+	_, server := newRecorder(t)
+	server.Close()
+	result := (&Webhook{URL: server.URL}).Notify(context.Background(), testURLs(1))
+	if result.Err == nil || !result.Retryable() {
+		t.Errorf("closed server: err %v, retryable %t; want a retryable error", result.Err, result.Retryable())
+	}
+}
+
+func TestBaiduNotify(t *testing.T) {
+	rec, server := newRecorder(t, response{status: http.StatusOK, body: `{"success":2,"remain":98}`})
+	n := &Baidu{Endpoint: server.URL, Site: "https://example.com", Token: "tok", Client: server.Client()}
+
+	urls := testURLs(2)
+	if result := n.Notify(context.Background(), urls); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if rec.bodies[0] != strings.Join(urls, "\n") {
+		t.Errorf("body = %q", rec.bodies[0])
+	}
+	if rec.queries[0] != "site=https%3A%2F%2Fexample.com&token=tok" {
+		t.Errorf("query = %q", rec.queries[0])
+	}
+}
+
+func TestBaiduOverQuota(t *testing.T) {
+	_, server := newRecorder(t, response{status: http.StatusBadRequest, body: `{"error":400,"message":"over quota"}`})
+	n := &Baidu{Endpoint: server.URL, Site: "https://example.com", Token: "tok", Client: server.Client()}
+
+	result := n.Notify(context.Background(), testURLs(1))
+	if result.Err == nil || !result.Temporary || !result.Retryable() {
+		t.Fatalf("over quota: err %v, temporary %t, retryable %t; want a temporary error", result.Err, result.Temporary, result.Retryable())
+	}
+
+	// The quota resets at midnight in China
+	now := time.Now().In(baiduZone)
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, baiduZone)
+	if delay := midnight.Sub(now); (result.RetryAfter - delay).Abs() > time.Minute {
+		t.Errorf("RetryAfter = %v, want about %v", result.RetryAfter, delay)
+	}
+}
+
+func TestBaiduRejectedURLs(t *testing.T) {
+	_, server := newRecorder(t, response{status: http.StatusOK, body: `{"success":0,"remain":100,"not_same_site":["https://other.com/"]}`})
+	n := &Baidu{Endpoint: server.URL, Site: "https://example.com", Token: "tok", Client: server.Client()}
+	if result := n.Notify(context.Background(), []string{"https://other.com/"}); result.Err == nil || result.Retryable() {
+		t.Errorf("err %v, retryable %t; want a permanent error", result.Err, result.Retryable())
+	}
+}
+
+// testSender sends every batch with notifier, in batches of batchSize
+type testSender struct {
+	notifier  Notifier
+	batchSize int
+}
+
+func (s testSender) BatchSize(Submission) int { return s.batchSize }
+
+func (s testSender) Send(ctx context.Context, batch []Submission) Result {
+	urls := make([]string, len(batch))
+	for i, entry := range batch {
+		urls[i] = entry.URL
+	}
+	return s.notifier.Notify(ctx, urls)
+}
+
+// statuses counts the outbox entries by status
+func statuses(o *Outbox) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range o.List() {
+		counts[entry.Status]++
+	}
+	return counts
+}
+
+func TestOutboxBatches(t *testing.T) {
+	rec, server := newRecorder(t)
+	o := NewOutbox(filepath.Join(t.TempDir(), "submissions.json"))
+	sender := testSender{notifier: &Webhook{URL: server.URL, Client: server.Client()}, batchSize: 2}
+
+	urls := append(testURLs(5), "https://other.example/a/")
+	if err := o.Add("blog", []string{"webhook"}, urls, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	o.Flush(context.Background(), sender)
+
+	// Five URLs of one host in batches of two, and the other host on its own
+	if n := rec.requests(); n != 4 {
+		t.Errorf("sent %d requests, want 4", n)
+	}
+	for _, body := range rec.bodies {
+		var payload WebhookPayload
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range payload.URLs {
+			if Host(u) != payload.Host {
+				t.Errorf("batch for %s holds %s", payload.Host, u)
+			}
+		}
+	}
+	if counts := statuses(o); counts[SubmissionSubmitted] != 6 {
+		t.Errorf("statuses = %v, want 6 submitted", counts)
+	}
+}
+
+func TestOutboxRetries(t *testing.T) {
+	rec, server := newRecorder(t,
+		response{status: http.StatusTooManyRequests, retryAfter: "3600"},
+		response{status: http.StatusOK},
+	)
+	o := NewOutbox(filepath.Join(t.TempDir(), "submissions.json"))
+	sender := testSender{notifier: &IndexNow{Endpoint: server.URL, Key: "abc123", Client: server.Client()}}
+
+	if err := o.Add("blog", []string{"indexnow"}, testURLs(1), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	o.Flush(context.Background(), sender)
+
+	entry := o.List()[0]
+	if entry.Status != SubmissionPending || entry.Attempts != 1 || entry.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("after 429: %+v", entry)
+	}
+	if wait := entry.NextAttempt.Sub(start); wait < time.Hour || wait > time.Hour+time.Minute {
+		t.Errorf("retry scheduled after %v, want the 1h of Retry-After", wait)
+	}
+
+	// Not due yet
+	o.Flush(context.Background(), sender)
+	if n := rec.requests(); n != 1 {
+		t.Fatalf("sent %d requests before the retry was due", n)
+	}
+
+	// Make the retry due
+	o.mu.Lock()
+	o.entries[0].NextAttempt = time.Now()
+	o.mu.Unlock()
+	o.Flush(context.Background(), sender)
+	if entry := o.List()[0]; entry.Status != SubmissionSubmitted || entry.Attempts != 2 {
+		t.Errorf("after retry: %+v", entry)
+	}
+}
+
+func TestOutboxFailsPermanentErrors(t *testing.T) {
+	o := NewOutbox(filepath.Join(t.TempDir(), "submissions.json"))
+	sender := testSender{notifier: &IndexNow{Endpoint: "not a url", Key: "abc123"}}
+	if err := o.Add("blog", []string{"indexnow"}, testURLs(1), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	o.Flush(context.Background(), sender)
+	if entry := o.List()[0]; entry.Status != SubmissionFailed || entry.Attempts != 1 {
+		t.Errorf("after a local error: %+v, want failed after one attempt", entry)
+	}
+}
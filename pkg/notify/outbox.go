@@ -0,0 +1,366 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"hugo-publisher/pkg/atomicfile"
+)
+
+// Outbox tuning
+const (
+	SubmitDelay = 2 * time.Second // give the web server time to serve a new page
+	MaxAttempts = 8               // attempts before a URL is marked failed
+	MaxBackoff  = 6 * time.Hour   // upper bound of the retry delay
+	LogSize     = 1000            // finished submissions kept in the log
+)
+
+// Submission states
+const (
+	SubmissionPending   = "pending"
+	SubmissionSubmitted = "submitted"
+	SubmissionFailed    = "failed"
+)
+
+// Submission is one URL queued for one notifier of a site profile
+type Submission struct {
+	URL         string    `json:"url"`         // 提交的地址
+	Profile     string    `json:"profile"`     // 站点配置名称
+	Notifier    string    `json:"notifier"`    // 通知渠道名称
+	Status      string    `json:"status"`      // pending / submitted / failed
+	Attempts    int       `json:"attempts"`    // 已尝试次数
+	StatusCode  int       `json:"statusCode"`  // 最近一次响应状态码
+	LastError   string    `json:"lastError"`   // 最近一次失败原因
+	QueuedAt    time.Time `json:"queuedAt"`    // 加入队列的时间
+	NextAttempt time.Time `json:"nextAttempt"` // 下次尝试时间
+	SubmittedAt time.Time `json:"submittedAt"` // 提交成功的时间
+}
+
+// key identifies the queue a submission belongs to
+func (s Submission) key() string {
+	return s.Profile + "\x00" + s.Notifier
+}
+
+// Sender submits the batches of an Outbox. It resolves the notifier a
+// submission was queued for, so the outbox only stores names.
+type Sender interface {
+	// BatchSize is the largest batch the notifier of entry accepts, 0 for
+	// no limit
+	BatchSize(entry Submission) int
+	// Send submits a batch of URLs queued for the same notifier and host
+	Send(ctx context.Context, batch []Submission) Result
+}
+
+// Outbox stores the URLs to submit and the outcome of past submissions, and
+// submits them in the background with retries. It is persisted as JSON so
+// nothing is lost on restart.
+type Outbox struct {
+	// Warnf reports failed submissions and save errors; nil drops them
+	Warnf func(format string, args ...interface{})
+	// Finished is called with the submissions of a batch that were
+	// submitted or failed for good; nil ignores them
+	Finished func(entries []Submission)
+
+	mu      sync.Mutex
+	path    string
+	entries []Submission
+	wake    chan struct{}
+	cancel  context.CancelFunc
+}
+
+// NewOutbox returns an outbox persisted at path; call Load to read it
+func NewOutbox(path string) *Outbox {
+	return &Outbox{path: path, wake: make(chan struct{}, 1)}
+}
+
+// Load reads the outbox file
+func (o *Outbox) Load() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, err := os.ReadFile(o.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &o.entries); err != nil {
+		return fmt.Errorf("failed to parse submission outbox %s: %v", o.path, err)
+	}
+	return nil
+}
+
+// saveLocked trims the log and writes the outbox file; the caller must
+// hold o.mu
+func (o *Outbox) saveLocked() error {
+	finished := 0
+	for i := len(o.entries) - 1; i >= 0; i-- {
+		if o.entries[i].Status == SubmissionPending {
+			continue
+		}
+		finished++
+		if finished > LogSize {
+			o.entries = append(o.entries[:i], o.entries[i+1:]...)
+		}
+	}
+
+	data, err := json.MarshalIndent(o.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(o.path, data, 0600)
+}
+
+// Add queues urls for each of the named profile's notifiers, to be sent at
+// at. A URL already waiting for a notifier is not queued for it twice.
+func (o *Outbox) Add(profile string, notifiers []string, urls []string, at time.Time) error {
+	o.mu.Lock()
+	pending := make(map[string]bool)
+	for _, entry := range o.entries {
+		if entry.Status == SubmissionPending && entry.Profile == profile {
+			pending[entry.key()+"\x00"+entry.URL] = true
+		}
+	}
+	for _, notifier := range notifiers {
+		for _, u := range urls {
+			entry := Submission{
+				URL:         u,
+				Profile:     profile,
+				Notifier:    notifier,
+				Status:      SubmissionPending,
+				QueuedAt:    time.Now(),
+				NextAttempt: at,
+			}
+			if pending[entry.key()+"\x00"+u] {
+				continue
+			}
+			pending[entry.key()+"\x00"+u] = true
+			o.entries = append(o.entries, entry)
+		}
+	}
+	err := o.saveLocked()
+	o.mu.Unlock()
+
+	o.notify()
+	return err
+}
+
+// List returns a copy of the outbox, newest first
+func (o *Outbox) List() []Submission {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := make([]Submission, len(o.entries))
+	copy(entries, o.entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].QueuedAt.After(entries[j].QueuedAt) })
+	return entries
+}
+
+// RetryFailed puts every failed submission back in the queue
+func (o *Outbox) RetryFailed() error {
+	o.mu.Lock()
+	now := time.Now()
+	for i := range o.entries {
+		if o.entries[i].Status == SubmissionFailed {
+			o.entries[i].Status = SubmissionPending
+			o.entries[i].Attempts = 0
+			o.entries[i].NextAttempt = now
+		}
+	}
+	err := o.saveLocked()
+	o.mu.Unlock()
+
+	o.notify()
+	return err
+}
+
+// due returns the pending URLs whose attempt is due grouped per profile,
+// notifier and host, and when the next attempt is due (zero when none is).
+// batchSize returns the largest batch a group's notifier accepts.
+func (o *Outbox) due(now time.Time, batchSize func(Submission) int) ([][]Submission, time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	groups := make(map[string][]Submission)
+	var order []string
+	var next time.Time
+	for _, entry := range o.entries {
+		if entry.Status != SubmissionPending {
+			continue
+		}
+		if entry.NextAttempt.After(now) {
+			if next.IsZero() || entry.NextAttempt.Before(next) {
+				next = entry.NextAttempt
+			}
+			continue
+		}
+
+		// Every URL of a request must belong to the request's host
+		group := entry.key() + "\x00" + Host(entry.URL)
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], entry)
+	}
+
+	var batches [][]Submission
+	for _, group := range order {
+		entries := groups[group]
+		size := batchSize(entries[0])
+		if size <= 0 {
+			size = len(entries)
+		}
+		for len(entries) > 0 {
+			n := len(entries)
+			if n > size {
+				n = size
+			}
+			batches = append(batches, entries[:n])
+			entries = entries[n:]
+		}
+	}
+	return batches, next
+}
+
+// record stores the outcome of a batch: submitted, scheduled for a retry
+// with exponential backoff, or failed for good. It returns the entries that
+// are finished.
+func (o *Outbox) record(batch []Submission, result Result) ([]Submission, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	inBatch := make(map[string]bool, len(batch))
+	for _, entry := range batch {
+		inBatch[entry.key()+"\x00"+entry.URL] = true
+	}
+
+	now := time.Now()
+	var finished []Submission
+	for i := range o.entries {
+		entry := &o.entries[i]
+		if entry.Status != SubmissionPending || !inBatch[entry.key()+"\x00"+entry.URL] {
+			continue
+		}
+
+		entry.Attempts++
+		entry.StatusCode = result.StatusCode
+		if result.Err == nil {
+			entry.Status = SubmissionSubmitted
+			entry.SubmittedAt = now
+			entry.LastError = ""
+			finished = append(finished, *entry)
+			continue
+		}
+
+		entry.LastError = result.Err.Error()
+		if !result.Retryable() || entry.Attempts >= MaxAttempts {
+			entry.Status = SubmissionFailed
+			finished = append(finished, *entry)
+			continue
+		}
+		delay := result.RetryAfter
+		if delay <= 0 {
+			delay = backoff(entry.Attempts)
+		}
+		entry.NextAttempt = now.Add(delay)
+	}
+	return finished, o.saveLocked()
+}
+
+// backoff returns the delay before retry number attempts: 1 minute,
+// doubling up to MaxBackoff
+func backoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff {
+		delay = MaxBackoff
+	}
+	return delay
+}
+
+// warnf reports a problem through Warnf
+func (o *Outbox) warnf(format string, args ...interface{}) {
+	if o.Warnf != nil {
+		o.Warnf(format, args...)
+	}
+}
+
+// notify wakes the outbox loop after URLs were queued
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// submit sends the due batches and records their outcome
+func (o *Outbox) submit(ctx context.Context, sender Sender, batches [][]Submission) {
+	for _, batch := range batches {
+		result := sender.Send(ctx, batch)
+		if result.Err != nil {
+			o.warnf("Failed to notify %s: %v", batch[0].Notifier, result.Err)
+		}
+		finished, err := o.record(batch, result)
+		if err != nil {
+			o.warnf("Failed to save submission outbox: %v", err)
+		}
+		if len(finished) > 0 && o.Finished != nil {
+			o.Finished(finished)
+		}
+	}
+}
+
+// run submits due batches until ctx is cancelled
+func (o *Outbox) run(ctx context.Context, sender Sender) {
+	for {
+		batches, next := o.due(time.Now(), sender.BatchSize)
+		o.submit(ctx, sender, batches)
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-o.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Flush submits every pending URL that is due within SubmitDelay once, for
+// callers that exit instead of running the loop. URLs that fail stay queued
+// for the next Start to retry.
+func (o *Outbox) Flush(ctx context.Context, sender Sender) {
+	batches, _ := o.due(time.Now().Add(SubmitDelay), sender.BatchSize)
+	o.submit(ctx, sender, batches)
+}
+
+// Start launches the loop submitting queued URLs as they become due
+func (o *Outbox) Start(ctx context.Context, sender Sender) {
+	ctx, o.cancel = context.WithCancel(ctx)
+	go o.run(ctx, sender)
+}
+
+// Stop ends the loop
+func (o *Outbox) Stop() {
+	if o.cancel != nil {
+		o.cancel()
+	}
+}
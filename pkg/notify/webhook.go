@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// webhookBatchSize keeps webhook payloads reasonably small
+const webhookBatchSize = 1000
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook body, as
+// "sha256=<hex>", when the webhook has a secret
+const SignatureHeader = "X-Signature-256"
+
+// WebhookPayload is the JSON body posted to a webhook
+type WebhookPayload struct {
+	Host string   `json:"host"`
+	URLs []string `json:"urls"`
+}
+
+// Webhook posts changed URLs as JSON to any HTTP endpoint
+type Webhook struct {
+	URL    string
+	Secret string // signs the body when set, see SignatureHeader
+	Client *http.Client
+}
+
+// BatchSize implements Notifier
+func (n *Webhook) BatchSize() int {
+	return webhookBatchSize
+}
+
+// Notify implements Notifier
+func (n *Webhook) Notify(ctx context.Context, urls []string) Result {
+	if len(urls) == 0 {
+		return Result{}
+	}
+	if n.URL == "" {
+		return Result{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("webhook URL is not set")}
+	}
+
+	body, err := json.Marshal(WebhookPayload{Host: Host(urls[0]), URLs: urls})
+	if err != nil {
+		return localError("failed to marshal webhook payload: %v", err)
+	}
+
+	header := http.Header{}
+	if n.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.Secret))
+		mac.Write(body)
+		header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	result, _ := post(ctx, n.Client, "webhook", n.URL, "application/json", body, header)
+	return result
+}
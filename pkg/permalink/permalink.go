@@ -0,0 +1,247 @@
+// Package permalink computes the public URL path of a post the way Hugo
+// does: a front matter url wins, then the permalink pattern configured for
+// the post's section, then Hugo's default path-based URL.
+package permalink
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Page describes a post for URL resolution
+type Page struct {
+	Path  string    // content path relative to the content dir, slash separated (posts/2025-11-06/hello.md)
+	Title string    // front matter title
+	Slug  string    // front matter slug
+	URL   string    // front matter url, overrides every pattern
+	Date  time.Time // publish date used by the date tokens
+}
+
+// Resolver expands permalink patterns
+type Resolver struct {
+	Patterns map[string]string // section -> pattern, e.g. posts -> /:year/:month/:slug/
+	Default  string            // pattern for sections without their own, "" for Hugo's default
+}
+
+// ErrNoDate is returned for a pattern with date tokens when the page has no
+// date. Filling in the current date instead would give a URL that changes
+// every day.
+var ErrNoDate = errors.New("permalink: the pattern has date tokens but the page has no date")
+
+// dateTokens are the tokens expanded from the page date
+var dateTokens = map[string]bool{
+	":year": true, ":month": true, ":monthname": true, ":day": true,
+	":weekday": true, ":weekdayname": true, ":yearday": true,
+}
+
+// tokenPattern matches a permalink token, including Hugo's :sections[a:b] slices
+var tokenPattern = regexp.MustCompile(`:[a-z]+(\[[^\]]*\])?`)
+
+// Section returns the top-level section of a page
+func (p Page) Section() string {
+	section, _, found := strings.Cut(p.Path, "/")
+	if !found {
+		return ""
+	}
+	return section
+}
+
+// Sections returns the directories between the content dir and the page.
+// For a page bundle the bundle directory itself is not a section.
+func (p Page) Sections() []string {
+	dir := path.Dir(p.Path)
+	if p.IsBundle() {
+		dir = path.Dir(dir)
+	}
+	if dir == "." || dir == "" {
+		return nil
+	}
+	return strings.Split(dir, "/")
+}
+
+// IsBundle reports whether the page is a leaf bundle (index.md)
+func (p Page) IsBundle() bool {
+	return strings.TrimSuffix(path.Base(p.Path), path.Ext(p.Path)) == "index"
+}
+
+// ContentBaseName is the file name without extension, or the directory name
+// for a page bundle
+func (p Page) ContentBaseName() string {
+	if p.IsBundle() {
+		return path.Base(path.Dir(p.Path))
+	}
+	return strings.TrimSuffix(path.Base(p.Path), path.Ext(p.Path))
+}
+
+// Path returns the site-relative URL path of page. It fails with ErrNoDate
+// when the pattern needs a date the page does not have.
+func (r Resolver) Path(page Page) (string, error) {
+	if page.URL != "" {
+		return ensureSlashes(page.URL), nil
+	}
+
+	pattern, ok := r.Patterns[page.Section()]
+	if !ok {
+		pattern = r.Default
+	}
+	if pattern == "" {
+		return defaultPath(page), nil
+	}
+	expanded, err := Expand(pattern, page)
+	if err != nil {
+		return "", err
+	}
+	return ensureSlashes(expanded), nil
+}
+
+// defaultPath is the URL Hugo uses without a permalink pattern: the content
+// directory path followed by the slug (or file name)
+func defaultPath(page Page) string {
+	name := page.ContentBaseName()
+	if page.Slug != "" {
+		name = URLize(page.Slug)
+	}
+	return ensureSlashes(path.Join(append(page.Sections(), name)...) + "/")
+}
+
+// Expand replaces the permalink tokens in pattern with values of page.
+// Unknown tokens are left as they are.
+func Expand(pattern string, page Page) (string, error) {
+	if page.Date.IsZero() {
+		for _, token := range tokenPattern.FindAllString(pattern, -1) {
+			if dateTokens[token] {
+				return "", ErrNoDate
+			}
+		}
+	}
+
+	return tokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		if value, ok := tokenValue(token, page); ok {
+			return value
+		}
+		return token
+	}), nil
+}
+
+func tokenValue(token string, page Page) (string, bool) {
+	date := page.Date
+	switch token {
+	case ":year":
+		return strconv.Itoa(date.Year()), true
+	case ":month":
+		return fmt.Sprintf("%02d", int(date.Month())), true
+	case ":monthname":
+		return strings.ToLower(date.Month().String()), true
+	case ":day":
+		return fmt.Sprintf("%02d", date.Day()), true
+	case ":weekday":
+		return strconv.Itoa(int(date.Weekday())), true
+	case ":weekdayname":
+		return strings.ToLower(date.Weekday().String()), true
+	case ":yearday":
+		return strconv.Itoa(date.YearDay()), true
+	case ":section":
+		return page.Section(), true
+	case ":sections":
+		return strings.Join(page.Sections(), "/"), true
+	case ":title":
+		return URLize(page.Title), true
+	case ":slug":
+		if page.Slug != "" {
+			return URLize(page.Slug), true
+		}
+		return URLize(page.Title), true
+	case ":slugorfilename", ":slugorcontentbasename":
+		if page.Slug != "" {
+			return URLize(page.Slug), true
+		}
+		return page.ContentBaseName(), true
+	case ":contentbasenameorslug":
+		if name := page.ContentBaseName(); name != "" {
+			return name, true
+		}
+		return URLize(page.Slug), true
+	case ":filename", ":contentbasename":
+		return page.ContentBaseName(), true
+	}
+
+	if strings.HasPrefix(token, ":sections[") {
+		return sliceSections(token, page.Sections())
+	}
+	return "", false
+}
+
+// sliceSections implements :sections[i], :sections[a:b] and :sections[last]
+func sliceSections(token string, sections []string) (string, bool) {
+	spec := strings.TrimSuffix(strings.TrimPrefix(token, ":sections["), "]")
+	index := func(s string, fallback int) (int, bool) {
+		switch s {
+		case "":
+			return fallback, true
+		case "last":
+			return len(sections) - 1, true
+		}
+		n, err := strconv.Atoi(s)
+		return n, err == nil
+	}
+
+	from, to, isRange := strings.Cut(spec, ":")
+	start, ok1 := index(from, 0)
+	if !isRange {
+		if !ok1 || start < 0 || start >= len(sections) {
+			return "", ok1
+		}
+		return sections[start], true
+	}
+
+	end, ok2 := index(to, len(sections))
+	if !ok1 || !ok2 {
+		return "", false
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(sections) {
+		end = len(sections)
+	}
+	if start >= end {
+		return "", true
+	}
+	return strings.Join(sections[start:end], "/"), true
+}
+
+// URLize turns a title or slug into a URL path segment the way Hugo's
+// urlize does: lower case, spaces to hyphens, punctuation dropped
+func URLize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' || r == '/':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// ensureSlashes gives p a single leading slash and collapses the empty
+// segments left by empty tokens. A trailing slash is kept only if p had one,
+// as Hugo does for patterns like /:year/:slug.html.
+func ensureSlashes(p string) string {
+	segments := strings.FieldsFunc(p, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return "/"
+	}
+	joined := "/" + strings.Join(segments, "/")
+	if strings.HasSuffix(p, "/") {
+		joined += "/"
+	}
+	return joined
+}
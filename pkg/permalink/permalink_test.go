@@ -0,0 +1,96 @@
+package permalink
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolverPath(t *testing.T) {
+	date := time.Date(2025, time.March, 7, 10, 0, 0, 0, time.UTC)
+	post := Page{Path: "posts/2025-03-07/hello-world.md", Title: "Hello World", Date: date}
+	bundle := Page{Path: "posts/tech/go/my-trip/index.md", Title: "My Trip", Slug: "trip", Date: date}
+	nested := Page{Path: "docs/guide/setup/install.md", Title: "Install", Date: date}
+
+	tests := []struct {
+		name    string
+		pattern string
+		page    Page
+		want    string
+	}{
+		{"front matter url", "/:year/:slug/", Page{Path: "posts/a.md", URL: "about", Date: date}, "/about"},
+		{"default", "", post, "/posts/2025-03-07/hello-world/"},
+		{"default slug", "", Page{Path: "posts/a.md", Slug: "Custom Slug"}, "/posts/custom-slug/"},
+		{"default bundle", "", bundle, "/posts/tech/go/trip/"},
+		{"date", "/:year/:month/:day/:slug/", post, "/2025/03/07/hello-world/"},
+		{"date names", "/:monthname/:weekdayname/:weekday/:yearday/", post, "/march/friday/5/66/"},
+		{"title", "/:title/", post, "/hello-world/"},
+		{"section", "/:section/:slug/", nested, "/docs/install/"},
+		{"sections", "/:sections/:slug/", nested, "/docs/guide/setup/install/"},
+		{"sections of a bundle", "/:sections/:slug/", bundle, "/posts/tech/go/trip/"},
+		{"sections index", "/:sections[1]/:slug/", nested, "/guide/install/"},
+		{"sections last", "/:sections[last]/:slug/", nested, "/setup/install/"},
+		{"sections range", "/:sections[1:]/:slug/", nested, "/guide/setup/install/"},
+		{"sections head", "/:sections[:2]/:slug/", nested, "/docs/guide/install/"},
+		{"sections clamped", "/:sections[1:9]/:slug/", nested, "/guide/setup/install/"},
+		{"sections empty range", "/:sections[2:1]/:slug/", nested, "/install/"},
+		{"sections out of range", "/:sections[5]/:slug/", nested, "/install/"},
+		{"contentbasename", "/:contentbasename/", bundle, "/my-trip/"},
+		{"filename", "/:filename/", post, "/hello-world/"},
+		{"slugorfilename", "/:slugorfilename/", post, "/hello-world/"},
+		{"slugorcontentbasename", "/:slugorcontentbasename/", bundle, "/trip/"},
+		{"contentbasenameorslug", "/:contentbasenameorslug/", bundle, "/my-trip/"},
+		{"no trailing slash", "/:year/:slug.html", post, "/2025/hello-world.html"},
+		{"unknown token", "/:unknown/:slug/", post, "/:unknown/hello-world/"},
+		{"undated without date tokens", "/:slug/", Page{Path: "posts/a.md", Title: "A"}, "/a/"},
+	}
+	for _, tt := range tests {
+		resolver := Resolver{Default: tt.pattern}
+		got, err := resolver.Path(tt.page)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Path(%q) = %s, want %s", tt.name, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestResolverPathSectionPatterns(t *testing.T) {
+	resolver := Resolver{
+		Patterns: map[string]string{"posts": "/blog/:slug/"},
+		Default:  "/:sections/:filename/",
+	}
+	if got, _ := resolver.Path(Page{Path: "posts/a.md", Slug: "first"}); got != "/blog/first/" {
+		t.Errorf("posts section = %s, want /blog/first/", got)
+	}
+	if got, _ := resolver.Path(Page{Path: "docs/b.md"}); got != "/docs/b/" {
+		t.Errorf("other section = %s, want /docs/b/", got)
+	}
+}
+
+func TestResolverPathNeedsDate(t *testing.T) {
+	resolver := Resolver{Default: "/:year/:month/:slug/"}
+	if _, err := resolver.Path(Page{Path: "posts/a.md", Title: "A"}); !errors.Is(err, ErrNoDate) {
+		t.Errorf("Path of an undated post = %v, want ErrNoDate", err)
+	}
+	// A front matter url does not need the date
+	if got, err := resolver.Path(Page{Path: "posts/a.md", URL: "/a/"}); err != nil || got != "/a/" {
+		t.Errorf("Path with a url = %s, %v", got, err)
+	}
+}
+
+func TestURLize(t *testing.T) {
+	tests := map[string]string{
+		"Hello World":       "hello-world",
+		"  C++ & Go!  ":     "c--go",
+		"静态 网站":             "静态-网站",
+		"already-slug_v1.2": "already-slug_v1.2",
+	}
+	for in, want := range tests {
+		if got := URLize(in); got != want {
+			t.Errorf("URLize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
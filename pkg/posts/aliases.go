@@ -0,0 +1,47 @@
+package posts
+
+import (
+	"path"
+	"strings"
+)
+
+// aliasesAfterMove returns the aliases of a post whose URL moved from oldPath
+// to newPath: the old path is added, and the new one dropped in case the post
+// is being renamed back to an earlier URL
+func aliasesAfterMove(aliases []string, oldPath, newPath string) []string {
+	result := []string{}
+	hasOld := false
+	for _, alias := range aliases {
+		if SameURLPath(alias, newPath) {
+			continue
+		}
+		if SameURLPath(alias, oldPath) {
+			hasOld = true
+		}
+		result = append(result, alias)
+	}
+
+	if !hasOld {
+		result = append(result, oldPath)
+	}
+	return result
+}
+
+// AliasPath resolves a Hugo alias of the page at pagePath to a site-relative
+// path. Aliases without a leading slash are relative to the page's parent,
+// as in Hugo; absolute URLs are returned unchanged.
+func AliasPath(pagePath, alias string) string {
+	if alias == "" || strings.HasPrefix(alias, "/") || strings.Contains(alias, "://") {
+		return alias
+	}
+	resolved := path.Join(path.Dir(strings.TrimSuffix(pagePath, "/")), alias)
+	if strings.HasSuffix(alias, "/") {
+		resolved += "/"
+	}
+	return resolved
+}
+
+// SameURLPath compares two site-relative paths ignoring the trailing slash
+func SameURLPath(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
@@ -0,0 +1,93 @@
+package posts
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/vfs"
+)
+
+// fsys returns the file system holding the posts
+func (s Store) fsys() vfs.FS {
+	if s.FS != nil {
+		return s.FS
+	}
+	return vfs.Dir(s.Dir)
+}
+
+// name returns the name of filePath in the store's file system, or false
+// when filePath is outside the posts directory
+func (s Store) name(filePath string) (string, bool) {
+	rel, err := filepath.Rel(s.Dir, filePath)
+	if err != nil || (rel != "." && !filepath.IsLocal(rel)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// path returns the file path of a name in the store's file system
+func (s Store) path(name string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(name))
+}
+
+// outside is the error for a file path outside the posts directory
+func outside(op, filePath string) error {
+	return &fs.PathError{Op: op, Path: filePath, Err: fs.ErrInvalid}
+}
+
+// readFile returns the content of the post file at filePath
+func (s Store) readFile(filePath string) ([]byte, error) {
+	name, ok := s.name(filePath)
+	if !ok {
+		return nil, outside("open", filePath)
+	}
+	return s.fsys().ReadFile(name)
+}
+
+// exists reports whether filePath exists in the store
+func (s Store) exists(filePath string) bool {
+	name, ok := s.name(filePath)
+	if !ok {
+		return false
+	}
+	_, err := s.fsys().Stat(name)
+	return err == nil
+}
+
+// removeFile removes a file referenced by a post. Files outside the posts
+// directory can only be reached on disk, so with another file system they
+// are left alone.
+func (s Store) removeFile(filePath string) error {
+	if name, ok := s.name(filePath); ok {
+		return s.fsys().Remove(name)
+	}
+	if s.FS != nil {
+		return outside("remove", filePath)
+	}
+	return os.Remove(filePath)
+}
+
+// Page reads and parses the post file at filePath
+func (s Store) Page(filePath string) (*frontmatter.Page, error) {
+	content, err := s.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	page, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析文章 Front Matter: %v", err)
+	}
+	return page, nil
+}
+
+// WritePage writes page back to the post file at filePath
+func (s Store) WritePage(filePath string, page *frontmatter.Page) error {
+	name, ok := s.name(filePath)
+	if !ok {
+		return outside("write", filePath)
+	}
+	return s.fsys().WriteFile(name, page.Bytes(), 0644)
+}
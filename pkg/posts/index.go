@@ -0,0 +1,203 @@
+package posts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/frontmatter"
+)
+
+// Index keeps the Info of the posts of one or more stores, keyed by path,
+// modification time and size, so listing only re-reads the files that
+// changed since the last listing. It is persisted as JSON, so a restart
+// does not re-read every post either.
+type Index struct {
+	// Warnf reports failures to save the index; nil drops them
+	Warnf func(format string, args ...interface{})
+
+	mu    sync.Mutex
+	path  string
+	dirs  map[string]map[string]indexEntry // posts directory -> post ID -> entry
+	texts map[string]*textIndex            // posts directory -> full-text index, kept in memory only
+}
+
+// indexVersion changes whenever Info gains fields, so entries cached by an
+// older version are read again rather than listed with the fields empty
+const indexVersion = 2
+
+// indexFile is the persisted index
+type indexFile struct {
+	Version int                              `json:"version"`
+	Dirs    map[string]map[string]indexEntry `json:"dirs"`
+}
+
+// indexEntry is the cached Info of one post file
+type indexEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Info    Info      `json:"info"`
+}
+
+// current reports whether the entry was read from the file described by info
+func (e indexEntry) current(info fs.FileInfo) bool {
+	return e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// NewIndex returns an index persisted at path, or kept in memory only when
+// path is ""; call Load to read it
+func NewIndex(path string) *Index {
+	return &Index{path: path, dirs: make(map[string]map[string]indexEntry), texts: make(map[string]*textIndex)}
+}
+
+// Load reads the index file
+func (x *Index) Load() error {
+	if x.path == "" {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	data, err := os.ReadFile(x.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file indexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse post index %s: %v", x.path, err)
+	}
+	if file.Version == indexVersion && file.Dirs != nil {
+		x.dirs = file.Dirs
+	}
+	return nil
+}
+
+// saveLocked writes the index file; the caller must hold x.mu
+func (x *Index) saveLocked() error {
+	data, err := json.Marshal(indexFile{Version: indexVersion, Dirs: x.dirs})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(x.path, data, 0600)
+}
+
+// infos returns the Info of every post of s, re-reading only the files
+// whose size or modification time changed, and the IDs of the posts added,
+// changed or deleted since the last call. Entries of deleted files are
+// dropped.
+func (x *Index) infos(s Store) ([]Info, []string, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir := filepath.Clean(s.Dir)
+	x.mu.Lock()
+	cached := x.dirs[dir]
+	x.mu.Unlock()
+
+	// Read the changed files without holding the lock. Entry maps are
+	// replaced, never modified, so cached can be read unlocked.
+	entries := make(map[string]indexEntry, len(files))
+	infos := make([]Info, 0, len(files))
+	for _, file := range files {
+		entry, ok := cached[file.name]
+		if !ok || !entry.current(file.info) {
+			entry = indexEntry{
+				Size:    file.info.Size(),
+				ModTime: file.info.ModTime(),
+				Info:    s.Info(s.path(file.name)),
+			}
+		}
+		entries[file.name] = entry
+		infos = append(infos, entry.Info)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	// Compare against the entries stored now, which another listing may
+	// have replaced while the files were read
+	stored := x.dirs[dir]
+	var changed []string
+	for _, file := range files {
+		if entry, ok := stored[file.name]; !ok || !entry.current(file.info) {
+			changed = append(changed, file.name)
+		}
+	}
+	for name := range stored {
+		if _, ok := entries[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	x.dirs[dir] = entries
+
+	if len(changed) > 0 && x.path != "" {
+		if err := x.saveLocked(); err != nil && x.Warnf != nil {
+			x.Warnf("Failed to save post index: %v", err)
+		}
+	}
+	return infos, changed, nil
+}
+
+// infos returns the Info of every post, through the index when the store
+// has one. Status is computed for now, as scheduled posts go live and
+// published ones expire without their files changing.
+func (s Store) infos(now time.Time) ([]Info, error) {
+	if s.Index == nil {
+		files, err := s.Files()
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]Info, len(files))
+		for i, filePath := range files {
+			infos[i] = s.Info(filePath)
+		}
+		return infos, nil
+	}
+
+	infos, _, err := s.Index.infos(s)
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		info := &infos[i]
+		info.Status = Status(frontmatter.Meta{
+			Draft:       info.Draft,
+			Date:        info.Date,
+			PublishDate: info.PublishDate,
+			ExpiryDate:  info.ExpiryDate,
+		}, now)
+	}
+	return infos, nil
+}
+
+// Refresh brings the index entries of the store's posts up to date, so the
+// next listing does not have to re-read the files that changed on disk. It
+// returns the IDs of the posts added, changed or deleted since the index
+// last saw them; a store without an index has nothing to compare and
+// returns none.
+func (s Store) Refresh() ([]string, error) {
+	if s.Index == nil {
+		return nil, nil
+	}
+	if _, err := s.fsys().Stat("."); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	_, changed, err := s.Index.infos(s)
+	sort.Strings(changed)
+	return changed, err
+}
@@ -0,0 +1,219 @@
+package posts
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Named post storage layouts, accepted in place of a layout pattern
+const (
+	LayoutDate   = "date"   // <dir>/<YYYY-MM-DD>/<slug>.md, images in the upload directory
+	LayoutBundle = "bundle" // <dir>/<slug>/index.md, images next to index.md
+)
+
+// layoutPatterns maps the named layouts to their patterns
+var layoutPatterns = map[string]string{
+	LayoutDate:   "{{date}}/{{slug}}.md",
+	LayoutBundle: "{{slug}}/index.md",
+}
+
+// layoutToken matches a {{token}} of a layout pattern
+var layoutToken = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// BundleIndexFile is the content file of a Hugo leaf bundle
+const BundleIndexFile = "index.md"
+
+// LayoutPattern resolves a layout setting to a pattern; "" is the date layout
+func LayoutPattern(layout string) string {
+	if layout == "" {
+		layout = LayoutDate
+	}
+	if pattern, ok := layoutPatterns[layout]; ok {
+		return pattern
+	}
+	return layout
+}
+
+// ValidateLayout checks a layout setting: a named layout, or a relative .md
+// path pattern containing {{slug}} and only known tokens
+func ValidateLayout(layout string) error {
+	pattern := LayoutPattern(layout)
+	if path.Ext(pattern) != ".md" {
+		return fmt.Errorf("无效的文章存储结构: %s", layout)
+	}
+	hasSlug := false
+	for _, match := range layoutToken.FindAllStringSubmatch(pattern, -1) {
+		if _, ok := layoutValue(match[1], time.Time{}, ""); !ok {
+			return fmt.Errorf("无效的文章存储结构: %s (未知占位符 %s)", layout, match[0])
+		}
+		hasSlug = hasSlug || match[1] == "slug"
+	}
+	if !hasSlug {
+		return fmt.Errorf("无效的文章存储结构: %s", layout)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(ExpandLayout(pattern, time.Now(), "post"))) {
+		return fmt.Errorf("无效的文章存储结构: %s", layout)
+	}
+	return nil
+}
+
+// ExpandLayout fills a layout pattern for a post named name written at date
+func ExpandLayout(pattern string, date time.Time, name string) string {
+	return layoutToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		value, _ := layoutValue(layoutToken.FindStringSubmatch(token)[1], date, name)
+		return value
+	})
+}
+
+func layoutValue(token string, date time.Time, name string) (string, bool) {
+	switch token {
+	case "year":
+		return date.Format("2006"), true
+	case "month":
+		return date.Format("01"), true
+	case "day":
+		return date.Format("02"), true
+	case "date":
+		return date.Format("2006-01-02"), true
+	case "slug":
+		return name, true
+	}
+	return "", false
+}
+
+// NewPath returns where Create writes a new post named name at date
+func (s Store) NewPath(date time.Time, name string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(ExpandLayout(LayoutPattern(s.Layout), date, name)))
+}
+
+// IsBundle reports whether filePath is the index.md of a leaf bundle inside
+// the posts directory. An index.md directly in the posts directory is a
+// plain post.
+func (s Store) IsBundle(filePath string) bool {
+	return filepath.Base(filePath) == BundleIndexFile &&
+		filepath.Clean(filepath.Dir(filePath)) != filepath.Clean(s.Dir)
+}
+
+// Name returns the name a post is listed under when it has no title: the
+// file name, or the bundle directory for a page bundle
+func (s Store) Name(filePath string) string {
+	if s.IsBundle(filePath) {
+		return filepath.Base(filepath.Dir(filePath))
+	}
+	return strings.TrimSuffix(filepath.Base(filePath), ".md")
+}
+
+// Files returns the markdown files of all posts below the posts directory,
+// at any depth. A directory holding an index.md is a leaf bundle: only its
+// index.md is a post, the rest are its resources. Section pages (_index.md)
+// and hidden directories are skipped.
+func (s Store) Files() ([]string, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = s.path(file.name)
+	}
+	return paths, nil
+}
+
+// postFile is a post file found by files
+type postFile struct {
+	name string      // name in the store's file system, which is also the post ID
+	info fs.FileInfo // size and modification time
+}
+
+// files walks the posts directory for Files
+func (s Store) files() ([]postFile, error) {
+	fsys := s.fsys()
+	var files []postFile
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if name == "." {
+				return err
+			}
+			return nil // Skip directories we can't read
+		}
+
+		if !entry.IsDir() {
+			if !IsPostFile(entry.Name()) {
+				return nil
+			}
+			if info, err := entry.Info(); err == nil {
+				files = append(files, postFile{name: name, info: info})
+			}
+			return nil
+		}
+		if name == "." {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			return fs.SkipDir
+		}
+
+		indexName := path.Join(name, BundleIndexFile)
+		if info, err := fs.Stat(fsys, indexName); err == nil && !info.IsDir() {
+			files = append(files, postFile{name: indexName, info: info})
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsPostFile reports whether a file name is a post's markdown file
+func IsPostFile(name string) bool {
+	return filepath.Ext(name) == ".md" && name != "_index.md"
+}
+
+// Owner returns the ID of the post a file below the posts directory belongs
+// to, given the file's slash-separated name: the enclosing leaf bundle's
+// index.md for bundle resources, or the file itself when it is a post.
+// It returns false for anything else. Removed files are resolved by name.
+func (s Store) Owner(name string) (string, bool) {
+	fsys := s.fsys()
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		indexName := path.Join(dir, BundleIndexFile)
+		if indexName == name {
+			return name, true
+		}
+		if info, err := fs.Stat(fsys, indexName); err == nil && !info.IsDir() {
+			return indexName, true
+		}
+	}
+	if IsPostFile(path.Base(name)) {
+		return name, true
+	}
+	return "", false
+}
+
+// removeEmptyParents removes the directories left empty between filePath and
+// the posts directory after a post was deleted or moved
+func (s Store) removeEmptyParents(filePath string) {
+	name, ok := s.name(filePath)
+	if !ok {
+		return
+	}
+	fsys := s.fsys()
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if entries, err := fsys.ReadDir(dir); err != nil || len(entries) > 0 {
+			return
+		}
+		if err := fsys.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// isValidDatePath checks if a directory name is in YYYY-MM-DD format
+func isValidDatePath(dirName string) bool {
+	_, err := time.Parse("2006-01-02", dirName)
+	return err == nil
+}
@@ -0,0 +1,186 @@
+package posts
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/search"
+)
+
+// Info summarizes a post for listings
+type Info struct {
+	ID           string   `json:"id"` // 文章ID：相对于文章目录的路径，如 2025-11-06/hello.md
+	Title        string   `json:"title"`
+	CoverImage   string   `json:"coverImage"`
+	Slug         string   `json:"slug"`
+	Keywords     []string `json:"keywords"`
+	HiddenInList bool     `json:"hiddenInList"` // 封面是否在列表中隐藏
+	Date         string   `json:"date"`         // 发布日期
+	LastMod      string   `json:"lastmod"`      // 最后修改日期
+	PublishDate  string   `json:"publishDate"`  // 定时发布时间
+	ExpiryDate   string   `json:"expiryDate"`   // 过期时间
+	Draft        bool     `json:"draft"`        // 是否为草稿
+	Status       string   `json:"status"`       // 发布状态：draft / scheduled / published / expired
+	Tags         []string `json:"tags"`
+	Author       []string `json:"author"`
+	Weight       int      `json:"weight"`
+	WordCount    int      `json:"wordCount"` // 正文字数，中日韩文字每字计一词
+}
+
+// Query selects, orders and pages the posts returned by List
+type Query struct {
+	Filter
+	Search   string `json:"search"`   // 在标题、slug 和关键词中查找的文字
+	Sort     string `json:"sort"`     // 排序字段：lastmod / date / title / weight / wordCount，空表示 lastmod
+	Order    string `json:"order"`    // 排序方向：asc / desc，空表示标题和权重升序、其余降序
+	Page     int    `json:"page"`     // 页码，从 1 开始
+	PageSize int    `json:"pageSize"` // 每页数量，0 表示全部
+}
+
+// ListResult is one page of posts and the number of posts matching
+type ListResult struct {
+	Posts      []Info `json:"posts"`
+	TotalCount int    `json:"totalCount"`
+}
+
+// Info reads the front matter (YAML, TOML or JSON) of the post at filePath.
+// A post that cannot be read or parsed is listed under its file name.
+func (s Store) Info(filePath string) Info {
+	// Default title from filename (or bundle directory)
+	title := s.Name(filePath)
+	info := Info{ID: s.ID(filePath), Title: title, Keywords: []string{}, HiddenInList: true, Status: StatusPublished}
+
+	fileContent, err := s.readFile(filePath)
+	if err != nil {
+		return info // Return info with fallback title
+	}
+
+	page, err := frontmatter.Parse(fileContent)
+	if err != nil {
+		return info // Malformed front matter, keep the fallback title
+	}
+
+	meta := page.Meta
+	info.Title = meta.Title
+	info.Date = meta.Date
+	info.LastMod = meta.LastMod
+	info.PublishDate = meta.PublishDate
+	info.ExpiryDate = meta.ExpiryDate
+	info.Draft = meta.Draft
+	info.Status = Status(meta, time.Now())
+	info.Slug = meta.Slug
+	info.Keywords = meta.Keywords
+	info.Tags = meta.Tags
+	info.Author = meta.Author
+	info.Weight = meta.Weight
+	info.WordCount = search.WordCount(page.Body)
+	info.CoverImage = meta.Cover.Image
+	if meta.Cover.HiddenInList != nil {
+		info.HiddenInList = *meta.Cover.HiddenInList
+	}
+
+	// If title from frontmatter is empty, use the fallback
+	if info.Title == "" {
+		info.Title = title
+	}
+	return info
+}
+
+// List returns the posts matching q, most recently modified first unless
+// q says otherwise
+func (s Store) List(q Query) (ListResult, error) {
+	match, err := q.Filter.matcher()
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	allPosts := []Info{}
+	if _, err := s.fsys().Stat("."); errors.Is(err, fs.ErrNotExist) {
+		return ListResult{Posts: allPosts}, nil
+	}
+
+	infos, err := s.infos(time.Now())
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	// Prepare search term - convert to lowercase for case-insensitive search
+	searchTerm := strings.ToLower(strings.TrimSpace(q.Search))
+
+	for _, info := range infos {
+		if info.Title == "_index" || !match(info) {
+			continue
+		}
+		if searchTerm == "" || matchesSearch(info, searchTerm) {
+			allPosts = append(allPosts, info)
+		}
+	}
+
+	if err := sortInfos(allPosts, q.Sort, q.Order); err != nil {
+		return ListResult{}, err
+	}
+
+	startIndex, endIndex := pageBounds(len(allPosts), q.Page, q.PageSize)
+	return ListResult{Posts: allPosts[startIndex:endIndex], TotalCount: len(allPosts)}, nil
+}
+
+// pageBounds returns the slice bounds of the 1-based page of pageSize items
+// out of total; pageSize 0 selects every item
+func pageBounds(total, page, pageSize int) (int, int) {
+	if pageSize <= 0 {
+		return 0, total
+	}
+	if page < 1 {
+		page = 1
+	}
+	startIndex := (page - 1) * pageSize
+	if startIndex >= total {
+		return total, total
+	}
+	return startIndex, min(startIndex+pageSize, total)
+}
+
+// matchesSearch reports whether a post's title, slug or keywords contain the
+// lower-cased search term, or every word of it
+func matchesSearch(info Info, searchTerm string) bool {
+	lowerTitle := strings.ToLower(info.Title)
+	lowerSlug := strings.ToLower(info.Slug)
+
+	// 检查标题、slug 匹配（支持部分匹配）
+	if strings.Contains(lowerTitle, searchTerm) || strings.Contains(lowerSlug, searchTerm) {
+		return true
+	}
+
+	// 检查关键词匹配（支持部分匹配）
+	for _, keyword := range info.Keywords {
+		if strings.Contains(strings.ToLower(keyword), searchTerm) {
+			return true
+		}
+	}
+
+	// 如果还是没有匹配，尝试分词匹配
+	searchTerms := strings.Fields(searchTerm)
+	if len(searchTerm) <= 1 || len(searchTerms) <= 1 {
+		return false
+	}
+	for _, term := range searchTerms {
+		if strings.Contains(lowerTitle, term) || strings.Contains(lowerSlug, term) {
+			continue
+		}
+		// 检查关键词
+		keywordMatch := false
+		for _, keyword := range info.Keywords {
+			if strings.Contains(strings.ToLower(keyword), term) {
+				keywordMatch = true
+				break
+			}
+		}
+		if !keywordMatch {
+			return false
+		}
+	}
+	return true
+}
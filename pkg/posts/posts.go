@@ -0,0 +1,264 @@
+// Package posts stores the posts of a Hugo site as markdown files with front
+// matter. A Store is one posts directory; new posts are laid out following
+// its layout (date directories, page bundles or a custom pattern), and posts
+// written by hand at any depth are picked up too. Files are read and written
+// through a vfs.FS, the directory on disk unless another is given.
+package posts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/vfs"
+)
+
+// Store is a posts directory. File paths taken and returned by its methods
+// are Dir joined with a post's path in FS.
+type Store struct {
+	Dir    string // posts directory
+	Layout string // named layout or layout pattern for new posts; "" is LayoutDate
+	FS     vfs.FS // files of Dir; nil is vfs.Dir(Dir)
+	Index  *Index // cache of the posts' Info used by List; nil reads every post
+}
+
+// ID returns the stable ID of a post file: its slash separated path
+// relative to the posts directory
+func (s Store) ID(filePath string) string {
+	rel, err := filepath.Rel(s.Dir, filePath)
+	if err != nil {
+		return filepath.ToSlash(filePath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Resolve finds a post by its ID and returns its file path. Titles are
+// still accepted as a compatibility shim; they are ambiguous when two posts
+// share a title, so callers should pass IDs.
+func (s Store) Resolve(id string) (string, error) {
+	if strings.HasSuffix(id, ".md") {
+		// IDs are relative paths; never resolve outside the posts directory
+		if filepath.IsLocal(filepath.FromSlash(id)) {
+			name := path.Clean(id)
+			if info, err := s.fsys().Stat(name); err == nil && !info.IsDir() {
+				return s.path(name), nil
+			}
+		}
+	}
+
+	return s.FindByTitle(id)
+}
+
+// FindByTitle locates a post file by its title
+func (s Store) FindByTitle(title string) (string, error) {
+	// First try the traditional method (for backward compatibility)
+	// Create a filename based on title only
+	filename := fmt.Sprintf("%s.md", legacySafeTitle(title))
+
+	// Check all date directories for the file using safe title
+	fullPath, ok, err := s.findInDateDirs(filename)
+	if err != nil || ok {
+		return fullPath, err
+	}
+
+	// If not found with safe title, try to find by matching the actual title in front matter
+	// This handles cases where the title contains special characters, other layouts and page bundles
+	if fullPath, ok, err := s.findTitle(title); err != nil || ok {
+		return fullPath, err
+	}
+
+	return "", fmt.Errorf("文章未找到: %s", title)
+}
+
+// findTitle returns the first post whose front matter declares title
+func (s Store) findTitle(title string) (string, bool, error) {
+	files, err := s.Files()
+	if err != nil {
+		return "", false, err
+	}
+	for _, fullPath := range files {
+		content, err := s.readFile(fullPath)
+		if err != nil {
+			continue
+		}
+		if actualTitle, ok := frontMatterTitle(content); ok && actualTitle == title {
+			return fullPath, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// findInDateDirs looks for filename in the date directories (YYYY-MM-DD)
+// posts used to be saved in
+func (s Store) findInDateDirs(filename string) (string, bool, error) {
+	entries, err := s.fsys().ReadDir(".")
+	if err != nil {
+		return "", false, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !isValidDatePath(entry.Name()) {
+			continue
+		}
+		name := path.Join(entry.Name(), filename)
+		if _, err := s.fsys().Stat(name); err == nil {
+			return s.path(name), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Load returns the content of the post with the given ID (or title)
+func (s Store) Load(id string) (string, error) {
+	postFilePath, err := s.Resolve(id)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := s.readFile(postFilePath)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Names returns the names of all posts, as listed when they have no title.
+// A missing posts directory has no posts.
+func (s Store) Names() ([]string, error) {
+	names := make([]string, 0)
+	if _, err := s.fsys().Stat("."); errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	}
+
+	files, err := s.Files()
+	if err != nil {
+		return names, err
+	}
+	for _, file := range files {
+		names = append(names, s.Name(file))
+	}
+	return names, nil
+}
+
+// Duplicate reports whether a post with the given title already exists,
+// and returns its file path
+func (s Store) Duplicate(title string) (bool, string, error) {
+	safeTitle := SafeFilename(title)
+
+	// 创建文件名（不带日期前缀）
+	filename := fmt.Sprintf("%s.md", safeTitle)
+
+	// Check all date directories for the file
+	if fullPath, ok, err := s.findInDateDirs(filename); err != nil || ok {
+		return ok, fullPath, err
+	}
+
+	// The file the configured layout would write, e.g. a page bundle
+	layoutPath := s.NewPath(time.Now(), safeTitle)
+	if s.exists(layoutPath) {
+		return true, layoutPath, nil
+	}
+
+	// The filename may differ from the title (custom slug, hand-written posts),
+	// so also compare against the title declared in each front matter
+	fullPath, ok, err := s.findTitle(title)
+	return ok, fullPath, err
+}
+
+// frontMatterTitle returns the title declared in a post's front matter
+func frontMatterTitle(content []byte) (string, bool) {
+	page, err := frontmatter.Parse(content)
+	if err != nil || page.Meta.Title == "" {
+		return "", false
+	}
+	return page.Meta.Title, true
+}
+
+// SafeFilename creates the file (or bundle directory) name of a post from
+// its title or slug
+func SafeFilename(title string) string {
+	// Convert to lowercase
+	safeTitle := strings.ToLower(title)
+
+	// Replace spaces with hyphens
+	safeTitle = strings.ReplaceAll(safeTitle, " ", "-")
+
+	// Process each character
+	var result strings.Builder
+	for _, r := range safeTitle {
+		// Keep letters, digits, hyphens, and underscores
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			result.WriteRune(r)
+		} else {
+			// Everything else, such as punctuation, becomes a hyphen
+			result.WriteRune('-')
+		}
+	}
+
+	safeTitle = result.String()
+
+	// Replace multiple consecutive hyphens with a single hyphen
+	safeTitle = strings.ReplaceAll(safeTitle, "--", "-")
+	safeTitle = strings.ReplaceAll(safeTitle, "--", "-") // Do it twice to handle cases like "---"
+
+	// Limit title length to avoid overly long filenames
+	if len(safeTitle) > 50 {
+		safeTitle = safeTitle[:50]
+	}
+
+	// Trim leading and trailing hyphens and underscores
+	safeTitle = strings.Trim(safeTitle, "-_")
+
+	// If title becomes empty, use a default
+	if safeTitle == "" {
+		safeTitle = "post"
+	}
+
+	return safeTitle
+}
+
+// legacySafeTitle builds the filename FindByTitle has always looked up
+// first. It differs from SafeFilename in that unsupported characters are
+// dropped instead of being replaced with hyphens.
+func legacySafeTitle(title string) string {
+	safeTitle := strings.ToLower(title)                 // 全部转换为小写
+	safeTitle = strings.ReplaceAll(safeTitle, " ", "-") // 空格替换为连字符
+	safeTitle = strings.Map(func(r rune) rune {
+		// 只保留字母、数字、连字符和下划线
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, safeTitle)
+
+	// 限制标题长度以避免过长的文件名
+	if len(safeTitle) > 50 {
+		safeTitle = safeTitle[:50]
+	}
+
+	// 移除开头和结尾的连字符
+	safeTitle = strings.Trim(safeTitle, "-_")
+
+	// 如果标题变为空，使用默认名称
+	if safeTitle == "" {
+		safeTitle = "post"
+	}
+
+	return safeTitle
+}
+
+// SplitList splits a comma separated form value such as the keywords
+func SplitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
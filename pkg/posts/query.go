@@ -0,0 +1,221 @@
+package posts
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+)
+
+// Sort keys of a listing
+const (
+	SortLastMod   = "lastmod"   // last modified, falling back to the date
+	SortDate      = "date"      // date, falling back to the publish date
+	SortTitle     = "title"     // title, case-insensitively
+	SortWeight    = "weight"    // front matter weight
+	SortWordCount = "wordCount" // words in the body
+)
+
+// Sort directions
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Filter selects posts by their front matter. Zero fields match every post;
+// the others must all match.
+type Filter struct {
+	Status    string   `json:"status"`    // 发布状态：draft / scheduled / published / expired，空表示全部
+	Tags      []string `json:"tags"`      // 必须带有全部这些标签，不区分大小写
+	Keywords  []string `json:"keywords"`  // 必须带有全部这些关键词，不区分大小写
+	Author    string   `json:"author"`    // 作者之一，不区分大小写
+	From      string   `json:"from"`      // 日期不早于此，如 2024-01-01
+	To        string   `json:"to"`        // 日期不晚于此，如 2024-12-31，只写日期时包含当天
+	Cover     string   `json:"cover"`     // with 只列出有封面的文章，without 只列出没有封面的，空表示不限
+	Directory string   `json:"directory"` // 只列出此子目录下的文章，相对文章目录，如 2024
+}
+
+// Cover filter values
+const (
+	CoverWith    = "with"
+	CoverWithout = "without"
+)
+
+// matcher validates f and returns whether a post matches it
+func (f Filter) matcher() (func(Info) bool, error) {
+	if !ValidStatus(f.Status) {
+		return nil, fmt.Errorf("无效的文章状态: %s", f.Status)
+	}
+	if f.Cover != "" && f.Cover != CoverWith && f.Cover != CoverWithout {
+		return nil, fmt.Errorf("无效的封面筛选: %s", f.Cover)
+	}
+
+	var from, to time.Time
+	if f.From != "" {
+		var ok bool
+		if from, ok = frontmatter.ParseDate(f.From); !ok {
+			return nil, fmt.Errorf("无效的日期: %s", f.From)
+		}
+	}
+	if f.To != "" {
+		var ok bool
+		if to, ok = frontmatter.ParseDate(f.To); !ok {
+			return nil, fmt.Errorf("无效的日期: %s", f.To)
+		}
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(f.To)); err == nil {
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond) // The whole day
+		}
+	}
+
+	directory := ""
+	if f.Directory != "" {
+		directory = path.Clean(strings.Trim(strings.ReplaceAll(f.Directory, "\\", "/"), "/"))
+		if directory == "." {
+			directory = ""
+		}
+	}
+
+	return func(info Info) bool {
+		if f.Status != "" && info.Status != f.Status {
+			return false
+		}
+		for _, tag := range f.Tags {
+			if !containsFold(info.Tags, tag) {
+				return false
+			}
+		}
+		for _, keyword := range f.Keywords {
+			if !containsFold(info.Keywords, keyword) {
+				return false
+			}
+		}
+		if f.Author != "" && !containsFold(info.Author, f.Author) {
+			return false
+		}
+		if !from.IsZero() || !to.IsZero() {
+			date, ok := postDate(info)
+			if !ok || (!from.IsZero() && date.Before(from)) || (!to.IsZero() && date.After(to)) {
+				return false
+			}
+		}
+		if f.Cover != "" && (info.CoverImage != "") != (f.Cover == CoverWith) {
+			return false
+		}
+		if directory != "" && !strings.HasPrefix(info.ID, directory+"/") {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// containsFold reports whether values holds value, ignoring case and
+// surrounding white space
+func containsFold(values []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// postDate returns the date of a post: its date, or its publish date when
+// it has none
+func postDate(info Info) (time.Time, bool) {
+	if date, ok := frontmatter.ParseDate(info.Date); ok {
+		return date, true
+	}
+	return frontmatter.ParseDate(info.PublishDate)
+}
+
+// modDate returns when a post was last modified: its lastmod, or its date
+func modDate(info Info) (time.Time, bool) {
+	if date, ok := frontmatter.ParseDate(info.LastMod); ok {
+		return date, true
+	}
+	return postDate(info)
+}
+
+// sortKey is a post with the dates it sorts by, parsed once
+type sortKey struct {
+	info    Info
+	mod     time.Time // lastmod, or the date
+	date    time.Time // date, or the publish date
+	hasMod  bool
+	hasDate bool
+}
+
+// sortInfos orders infos by key in order. "" sorts by SortLastMod; an empty
+// order sorts titles and weights ascending and everything else newest or
+// longest first. Ties keep the most recently modified first, then go by ID.
+func sortInfos(infos []Info, key, order string) error {
+	var compare func(a, b sortKey) int
+	switch key {
+	case "", SortLastMod:
+		compare = func(a, b sortKey) int { return compareDates(a.mod, a.hasMod, b.mod, b.hasMod) }
+	case SortDate:
+		compare = func(a, b sortKey) int { return compareDates(a.date, a.hasDate, b.date, b.hasDate) }
+	case SortTitle:
+		compare = func(a, b sortKey) int {
+			return strings.Compare(strings.ToLower(a.info.Title), strings.ToLower(b.info.Title))
+		}
+	case SortWeight:
+		compare = func(a, b sortKey) int { return a.info.Weight - b.info.Weight }
+	case SortWordCount:
+		compare = func(a, b sortKey) int { return a.info.WordCount - b.info.WordCount }
+	default:
+		return fmt.Errorf("无效的排序字段: %s", key)
+	}
+
+	descending := key != SortTitle && key != SortWeight
+	switch order {
+	case "":
+	case OrderAsc:
+		descending = false
+	case OrderDesc:
+		descending = true
+	default:
+		return fmt.Errorf("无效的排序方向: %s", order)
+	}
+
+	keys := make([]sortKey, len(infos))
+	for i, info := range infos {
+		keys[i].info = info
+		keys[i].date, keys[i].hasDate = postDate(info)
+		keys[i].mod, keys[i].hasMod = modDate(info)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		c := compare(keys[i], keys[j])
+		if descending {
+			c = -c
+		}
+		if c == 0 {
+			c = -compareDates(keys[i].mod, keys[i].hasMod, keys[j].mod, keys[j].hasMod)
+		}
+		if c == 0 {
+			c = strings.Compare(keys[i].info.ID, keys[j].info.ID)
+		}
+		return c < 0
+	})
+	for i := range keys {
+		infos[i] = keys[i].info
+	}
+	return nil
+}
+
+// compareDates compares two dates; a missing one sorts before the oldest
+func compareDates(a time.Time, okA bool, b time.Time, okB bool) int {
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	return a.Compare(b)
+}
@@ -0,0 +1,150 @@
+package posts
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"hugo-publisher/pkg/search"
+)
+
+// SearchQuery is a full-text search of a store's posts
+type SearchQuery struct {
+	Filter
+	Text     string // words to find in titles, descriptions, tags and bodies
+	Page     int    // 1-based page number
+	PageSize int    // posts per page; 0 returns every match
+}
+
+// SearchHit is a post matching a search
+type SearchHit struct {
+	Info
+	Score   float64           `json:"score"`   // 相关度，仅在同一次搜索中可比较
+	Snippet []search.Fragment `json:"snippet"` // 命中位置附近的摘要，match 为 true 的片段是命中的词
+}
+
+// SearchResult is one page of search hits and the number of posts matching
+type SearchResult struct {
+	Hits       []SearchHit `json:"hits"`
+	TotalCount int         `json:"totalCount"`
+}
+
+// textIndex is the full-text index of one posts directory, with the size
+// and modification time of every file as it was indexed
+type textIndex struct {
+	index  *search.Index
+	stamps map[string]indexEntry // post ID -> entry without Info
+}
+
+// Search returns the posts matching every word of q.Text, most relevant
+// first, each with a snippet of where it matched. Only the files that
+// changed since the last search are read again when the store has an
+// Index.
+func (s Store) Search(q SearchQuery) (SearchResult, error) {
+	match, err := q.Filter.matcher()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	result := SearchResult{Hits: []SearchHit{}}
+	if _, err := s.fsys().Stat("."); errors.Is(err, fs.ErrNotExist) {
+		return result, nil
+	}
+
+	var text *search.Index
+	if s.Index != nil {
+		text, err = s.Index.text(s)
+	} else {
+		text, err = (&textIndex{index: search.NewIndex(), stamps: make(map[string]indexEntry)}).update(s)
+	}
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	infos, err := s.infos(time.Now())
+	if err != nil {
+		return SearchResult{}, err
+	}
+	byID := make(map[string]Info, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = info
+	}
+
+	for _, hit := range text.Search(q.Text) {
+		info, ok := byID[hit.ID]
+		if !ok || info.Title == "_index" || !match(info) {
+			continue
+		}
+		result.Hits = append(result.Hits, SearchHit{Info: info, Score: hit.Score})
+	}
+
+	result.TotalCount = len(result.Hits)
+	startIndex, endIndex := pageBounds(result.TotalCount, q.Page, q.PageSize)
+	result.Hits = result.Hits[startIndex:endIndex]
+	for i := range result.Hits {
+		result.Hits[i].Snippet = text.Snippet(result.Hits[i].ID, q.Text)
+	}
+	return result, nil
+}
+
+// text returns the full-text index of s, brought up to date
+func (x *Index) text(s Store) (*search.Index, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	dir := filepath.Clean(s.Dir)
+	t := x.texts[dir]
+	if t == nil {
+		t = &textIndex{index: search.NewIndex(), stamps: make(map[string]indexEntry)}
+		x.texts[dir] = t
+	}
+	return t.update(s)
+}
+
+// update re-indexes the posts of s whose size or modification time changed
+// and drops the deleted ones
+func (t *textIndex) update(s Store) (*search.Index, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		seen[file.name] = true
+		stamp, ok := t.stamps[file.name]
+		if ok && stamp.current(file.info) {
+			continue
+		}
+		t.index.Add(s.document(file.name))
+		t.stamps[file.name] = indexEntry{Size: file.info.Size(), ModTime: file.info.ModTime()}
+	}
+	for name := range t.stamps {
+		if !seen[name] {
+			t.index.Remove(name)
+			delete(t.stamps, name)
+		}
+	}
+	return t.index, nil
+}
+
+// document reads what is searched of the post name. A post that cannot be
+// parsed is found by its file name only.
+func (s Store) document(name string) search.Document {
+	filePath := s.path(name)
+	doc := search.Document{ID: name, Title: s.Name(filePath)}
+	page, err := s.Page(filePath)
+	if err != nil {
+		return doc
+	}
+
+	meta := page.Meta
+	if meta.Title != "" {
+		doc.Title = meta.Title
+	}
+	doc.Description = meta.Description
+	doc.Tags = append(append(append([]string{}, meta.Tags...), meta.Keywords...), meta.Slug)
+	doc.Body = page.Body
+	return doc
+}
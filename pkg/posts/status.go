@@ -0,0 +1,85 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+)
+
+// Publication states of a post, reported in Info.Status and accepted by the
+// Query status filter ("" lists every post)
+const (
+	StatusDraft     = "draft"     // draft: true
+	StatusScheduled = "scheduled" // publishDate (or date) in the future
+	StatusPublished = "published" // live on the next build
+	StatusExpired   = "expired"   // expiryDate in the past
+)
+
+// TimeFormat is how the publisher writes date-times into front matter
+const TimeFormat = "2006-01-02T15:04:05-07:00"
+
+// Status returns the state of a post at now, following the rules Hugo
+// applies without --buildDrafts, --buildFuture and --buildExpired
+func Status(meta frontmatter.Meta, now time.Time) string {
+	if meta.Draft {
+		return StatusDraft
+	}
+
+	// Hugo falls back to date when publishDate is not set
+	publishDate := meta.PublishDate
+	if publishDate == "" {
+		publishDate = meta.Date
+	}
+	if t, ok := frontmatter.ParseDate(publishDate); ok && t.After(now) {
+		return StatusScheduled
+	}
+
+	if t, ok := frontmatter.ParseDate(meta.ExpiryDate); ok && !t.After(now) {
+		return StatusExpired
+	}
+	return StatusPublished
+}
+
+// IsPublic reports whether a post is live, i.e. worth submitting to search
+// engines
+func IsPublic(meta frontmatter.Meta, now time.Time) bool {
+	return Status(meta, now) == StatusPublished
+}
+
+// ValidStatus reports whether status is a Query status filter value
+func ValidStatus(status string) bool {
+	switch status {
+	case "", StatusDraft, StatusScheduled, StatusPublished, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// FormatDates checks the publish and expiry dates entered in the editor and
+// returns them in the format written to front matter. Empty values stay
+// empty.
+func FormatDates(publishDate, expiryDate string) (string, string, error) {
+	var publish, expiry time.Time
+	if publishDate != "" {
+		t, ok := frontmatter.ParseDate(publishDate)
+		if !ok {
+			return "", "", fmt.Errorf("无效的发布时间: %s", publishDate)
+		}
+		publish = t
+		publishDate = t.Format(TimeFormat)
+	}
+	if expiryDate != "" {
+		t, ok := frontmatter.ParseDate(expiryDate)
+		if !ok {
+			return "", "", fmt.Errorf("无效的过期时间: %s", expiryDate)
+		}
+		expiry = t
+		expiryDate = t.Format(TimeFormat)
+	}
+
+	if !publish.IsZero() && !expiry.IsZero() && !expiry.After(publish) {
+		return "", "", fmt.Errorf("过期时间必须晚于发布时间")
+	}
+	return publishDate, expiryDate, nil
+}
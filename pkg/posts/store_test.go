@@ -0,0 +1,352 @@
+package posts
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/vfs"
+)
+
+// memoryStore returns a store of posts held in memory, laid out by layout
+func memoryStore(t *testing.T, layout string) (Store, *vfs.Memory) {
+	t.Helper()
+	fsys := vfs.NewMemory()
+	return Store{Dir: filepath.FromSlash("/site/content/posts"), Layout: layout, FS: fsys}, fsys
+}
+
+// ids returns the IDs of the listed posts
+func ids(infos []Info) []string {
+	list := make([]string, len(infos))
+	for i, info := range infos {
+		list[i] = info.ID
+	}
+	return list
+}
+
+func TestStoreCreateAndList(t *testing.T) {
+	s, _ := memoryStore(t, "")
+
+	fullPath, meta, err := s.Create(Post{Title: "Hello World", Content: "第一篇文章", Tags: []string{"Go"}, Author: "Aries"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantID := time.Now().Format("2006-01-02") + "/hello-world.md"
+	if id := s.ID(fullPath); id != wantID {
+		t.Errorf("Create wrote %s, want %s", id, wantID)
+	}
+	if meta.Title != "Hello World" {
+		t.Errorf("Create returned title %q", meta.Title)
+	}
+
+	result, err := s.List(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalCount != 1 || len(result.Posts) != 1 {
+		t.Fatalf("List returned %d of %d posts, want 1", len(result.Posts), result.TotalCount)
+	}
+	info := result.Posts[0]
+	if info.ID != wantID || info.Title != "Hello World" || info.Status != StatusPublished {
+		t.Errorf("List returned %+v", info)
+	}
+	if len(info.Tags) != 1 || info.Tags[0] != "Go" || len(info.Author) != 1 || info.Author[0] != "Aries" {
+		t.Errorf("List returned tags %v and author %v", info.Tags, info.Author)
+	}
+
+	content, err := s.Load(wantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(content, "\n\n第一篇文章") {
+		t.Errorf("Load returned %q", content)
+	}
+}
+
+func TestStoreCreateRefusesExistingFile(t *testing.T) {
+	for _, layout := range []string{"{{slug}}.md", "{{year}}/{{slug}}.md", LayoutDate, LayoutBundle} {
+		t.Run(layout, func(t *testing.T) {
+			s, fsys := memoryStore(t, layout)
+			fullPath, _, err := s.Create(Post{Title: "First", Slug: "same", Content: "first"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := s.Create(Post{Title: "Second", Slug: "same", Content: "second"}); err == nil {
+				t.Fatal("Create overwrote an existing post")
+			}
+			name, _ := s.name(fullPath)
+			data, err := fsys.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(data), "first") {
+				t.Errorf("existing post changed to %q", data)
+			}
+		})
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	s, fsys := memoryStore(t, "{{slug}}.md")
+	fullPath, _, err := s.Create(Post{Title: "Old", Slug: "old", Content: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A key the editor does not know about must survive the edit
+	name, _ := s.name(fullPath)
+	data, _ := fsys.ReadFile(name)
+	data = []byte(strings.Replace(string(data), "weight: 1\n", "weight: 1\nseries: [\"notes\"]\n", 1))
+	if err := fsys.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	url := func(filePath string, _ frontmatter.Meta) string { return "/" + s.Name(filePath) + "/" }
+	change, err := s.Update("old.md", Post{Title: "New", Slug: "new", Content: "new body"}, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID(change.Path) != "new.md" || change.OldURL != "/old/" || change.URL != "/new/" {
+		t.Errorf("Update moved %s (%s) to %s (%s)", s.ID(change.OldPath), change.OldURL, s.ID(change.Path), change.URL)
+	}
+	if _, err := fsys.Stat("old.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("old file still exists: %v", err)
+	}
+
+	page, err := s.Page(change.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Meta.Title != "New" || page.Body != "new body" {
+		t.Errorf("Update wrote title %q and body %q", page.Meta.Title, page.Body)
+	}
+	if len(page.Meta.Aliases) != 1 || page.Meta.Aliases[0] != "/old/" {
+		t.Errorf("Update wrote aliases %v, want [/old/]", page.Meta.Aliases)
+	}
+	if !strings.Contains(string(page.Bytes()), "series: [\"notes\"]") {
+		t.Errorf("Update dropped an unknown key:\n%s", page.Bytes())
+	}
+}
+
+func TestStoreUpdateMovesBundle(t *testing.T) {
+	s, fsys := memoryStore(t, LayoutBundle)
+	fullPath, _, err := s.Create(Post{Title: "Trip", Slug: "trip", Content: "![](photo.jpg)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("trip/photo.jpg", []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := s.Update(s.ID(fullPath), Post{Title: "Trip", Slug: "journey", Content: "![](photo.jpg)"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := s.ID(change.Path); id != "journey/index.md" {
+		t.Errorf("Update moved the bundle to %s", id)
+	}
+	if _, err := fsys.Stat("journey/photo.jpg"); err != nil {
+		t.Errorf("bundle resource not moved: %v", err)
+	}
+	if _, err := fsys.Stat("trip"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("old bundle still exists: %v", err)
+	}
+}
+
+// renameFails is a file system on which every rename fails
+type renameFails struct{ *vfs.Memory }
+
+func (renameFails) Rename(oldname, newname string) error {
+	return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrPermission}
+}
+
+func TestStoreUpdateFailedBundleMove(t *testing.T) {
+	s, fsys := memoryStore(t, LayoutBundle)
+	fullPath, _, err := s.Create(Post{Title: "Trip", Slug: "trip", Content: "old body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := fsys.ReadFile("trip/index.md")
+
+	s.FS = renameFails{fsys}
+	url := func(filePath string, _ frontmatter.Meta) string { return "/" + s.Name(filePath) + "/" }
+	if _, err := s.Update(s.ID(fullPath), Post{Title: "Trip", Slug: "journey", Content: "new body"}, url); err == nil {
+		t.Fatal("Update succeeded although the bundle could not be moved")
+	}
+	if after, _ := fsys.ReadFile("trip/index.md"); string(after) != string(before) {
+		t.Errorf("a failed move rewrote the old bundle:\n%s", after)
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	s, fsys := memoryStore(t, "{{year}}/{{month}}/{{slug}}.md")
+	fullPath, _, err := s.Create(Post{Title: "Gone", Content: "bye"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Create(Post{Title: "Kept", Content: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, page, err := s.Delete(s.ID(fullPath), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != fullPath || page == nil || page.Meta.Title != "Gone" {
+		t.Errorf("Delete returned %s and %v", deleted, page)
+	}
+	result, err := s.List(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(result.Posts); len(got) != 1 || !strings.HasSuffix(got[0], "/kept.md") {
+		t.Errorf("List after Delete returned %v", got)
+	}
+
+	// Deleting the last post removes the directories it leaves empty
+	if _, _, err := s.Delete(result.Posts[0].ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := fsys.ReadDir("."); err != nil || len(entries) != 0 {
+		t.Errorf("posts directory holds %v (%v), want nothing", entries, err)
+	}
+}
+
+func TestIndexRefresh(t *testing.T) {
+	s, fsys := memoryStore(t, "{{slug}}.md")
+	s.Index = NewIndex("")
+	if _, _, err := s.Create(Post{Title: "First", Slug: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent refreshes report the new post once between them
+	results := make(chan []string, 4)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			changed, err := s.Refresh()
+			if err != nil {
+				t.Error(err)
+			}
+			results <- changed
+		}()
+	}
+	var reported []string
+	for i := 0; i < cap(results); i++ {
+		reported = append(reported, <-results...)
+	}
+	if strings.Join(reported, ",") != "first.md" {
+		t.Errorf("concurrent refreshes reported %v", reported)
+	}
+
+	if changed, err := s.Refresh(); err != nil || len(changed) != 0 {
+		t.Errorf("Refresh of unchanged posts = %v, %v", changed, err)
+	}
+
+	// Edited, added and deleted posts are all reported
+	if err := fsys.WriteFile("first.md", []byte("---\ntitle: Edited\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Create(Post{Title: "Second", Slug: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := s.Refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changed, ",") != "first.md,second.md" {
+		t.Errorf("Refresh reported %v, want [first.md second.md]", changed)
+	}
+	if err := fsys.Remove("second.md"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err = s.Refresh(); err != nil || strings.Join(changed, ",") != "second.md" {
+		t.Errorf("Refresh after a delete = %v, %v, want [second.md]", changed, err)
+	}
+}
+
+func TestStoreListFiltersAndSorts(t *testing.T) {
+	s, fsys := memoryStore(t, "")
+	if err := fsys.MkdirAll("2024", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"2024/a.md": "---\ntitle: Alpha\ndate: 2024-03-01\ntags: [Go]\nweight: 3\n---\none two three\n",
+		"2024/b.md": "---\ntitle: beta\ndate: 2024-12-31T20:00:00Z\ntags: [go, Hugo]\nweight: 1\ncover:\n  image: x.png\n---\nword\n",
+		"2024/c.md": "---\ntitle: Gamma\ndate: 2023-05-01\ndraft: true\nweight: 2\n---\nmore words in this body\n",
+	}
+	for name, content := range files {
+		if err := fsys.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query Query
+		want  string
+	}{
+		{Query{Filter: Filter{Tags: []string{"go"}}, Sort: SortTitle}, "2024/a.md 2024/b.md"},
+		{Query{Filter: Filter{From: "2024-01-01", To: "2024-12-31", Cover: CoverWithout}}, "2024/a.md"},
+		{Query{Filter: Filter{Status: StatusDraft}}, "2024/c.md"},
+		{Query{Sort: SortWeight}, "2024/b.md 2024/c.md 2024/a.md"},
+		{Query{Sort: SortWordCount, Order: OrderAsc}, "2024/b.md 2024/a.md 2024/c.md"},
+		{Query{Sort: SortDate, Page: 2, PageSize: 2}, "2024/c.md"},
+	}
+	for _, tt := range tests {
+		result, err := s.List(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(ids(result.Posts), " "); got != tt.want {
+			t.Errorf("List(%+v) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	if _, err := s.List(Query{Sort: "size"}); err == nil {
+		t.Error("List accepted an unknown sort key")
+	}
+}
+
+func TestStoreSearch(t *testing.T) {
+	s, _ := memoryStore(t, "")
+	for _, p := range []Post{
+		{Title: "静态网站生成器", Content: "Hugo 是一个用 Go 编写的静态网站生成器。", Tags: []string{"Hugo"}},
+		{Title: "Notes", Content: "Nothing about websites here.", Draft: true},
+		{Title: "Go 并发", Content: "goroutine 和 channel。"},
+	} {
+		if _, _, err := s.Create(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := s.Search(SearchQuery{Text: "网站"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalCount != 1 || result.Hits[0].Title != "静态网站生成器" {
+		t.Fatalf("Search returned %+v", result.Hits)
+	}
+	var marked []string
+	for _, fragment := range result.Hits[0].Snippet {
+		if fragment.Match {
+			marked = append(marked, fragment.Text)
+		}
+	}
+	if strings.Join(marked, ",") != "网站" {
+		t.Errorf("snippet highlights %v, want [网站]", marked)
+	}
+
+	result, err = s.Search(SearchQuery{Text: "go", Filter: Filter{Status: StatusPublished}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalCount != 2 {
+		t.Errorf("Search for go returned %d posts, want 2", result.TotalCount)
+	}
+
+	if result, err = s.Search(SearchQuery{Text: "websites", Filter: Filter{Status: StatusPublished}}); err != nil || result.TotalCount != 0 {
+		t.Errorf("Search listed a draft: %+v, %v", result.Hits, err)
+	}
+}
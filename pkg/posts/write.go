@@ -0,0 +1,460 @@
+package posts
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+)
+
+// Post holds the fields of a post the editor form controls
+type Post struct {
+	Title        string
+	Content      string // markdown body
+	Description  string
+	Author       string
+	Cover        string // cover image path
+	Tags         []string
+	Weight       int // 0 is written as 1
+	Slug         string
+	Keywords     []string
+	HiddenInList bool // hide the cover in lists
+	Draft        bool
+	PublishDate  string // any date format frontmatter.ParseDate accepts
+	ExpiryDate   string
+}
+
+// URLFunc returns the site-relative URL of the post at filePath, or "" when
+// it has none (e.g. a date-based permalink for a post without a date)
+type URLFunc func(filePath string, meta frontmatter.Meta) string
+
+// Change describes a post before and after Update
+type Change struct {
+	OldPath string
+	OldMeta frontmatter.Meta
+	OldURL  string // site-relative URL before the edit, "" without a URLFunc
+	Path    string
+	Meta    frontmatter.Meta
+	URL     string
+}
+
+// fileName returns the name a post is saved under: its slug, or its title
+func fileName(title, slug string) string {
+	if slug != "" {
+		return SafeFilename(slug)
+	}
+	return SafeFilename(title)
+}
+
+// Create writes p as a new post at the path the layout gives for today, and
+// returns the path and the front matter written
+func (s Store) Create(p Post) (string, frontmatter.Meta, error) {
+	publishDate, expiryDate, err := FormatDates(p.PublishDate, p.ExpiryDate)
+	if err != nil {
+		return "", frontmatter.Meta{}, err
+	}
+
+	now := time.Now()
+	currentDate := now.Format("2006-01-02")
+
+	// 按站点的存储结构确定文件路径，如日期目录或页面包
+	fullPath := s.NewPath(now, fileName(p.Title, p.Slug))
+	weight := p.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+
+	// Format title and description - always use quoted format for consistency
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: \"%s\"\n", escapeString(p.Title))
+	fmt.Fprintf(&b, "date: %s\nlastmod: %s\n", currentDate, now.Format(TimeFormat))
+	fmt.Fprintf(&b, "description: \"%s\"\n", escapeString(p.Description))
+
+	// Format tags as YAML array
+	if len(p.Tags) > 0 {
+		quoted := make([]string, len(p.Tags))
+		for i, tag := range p.Tags {
+			quoted[i] = fmt.Sprintf("\"%s\"", escapeString(tag))
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if p.Author != "" {
+		fmt.Fprintf(&b, "author: [\"%s\"]\n", escapeString(p.Author))
+	}
+	if p.Cover != "" {
+		fmt.Fprintf(&b, "cover:\n    image: %s\n    hiddenInList: %t\n", p.Cover, p.HiddenInList)
+	}
+	if len(p.Keywords) > 0 {
+		b.WriteString("keywords:\n")
+		for _, keyword := range p.Keywords {
+			fmt.Fprintf(&b, "    - \"%s\"\n", escapeString(keyword))
+		}
+	}
+	fmt.Fprintf(&b, "weight: %d\n", weight)
+	if p.Slug != "" {
+		fmt.Fprintf(&b, "slug: \"%s\"\n", p.Slug)
+	}
+
+	// 草稿、定时发布和过期时间
+	if p.Draft {
+		b.WriteString("draft: true\n")
+	}
+	if publishDate != "" {
+		fmt.Fprintf(&b, "publishDate: %s\n", publishDate)
+	}
+	if expiryDate != "" {
+		fmt.Fprintf(&b, "expiryDate: %s\n", expiryDate)
+	}
+	fmt.Fprintf(&b, "---\n\n%s", p.Content)
+
+	// Create the date (or bundle) directory if it doesn't exist. A bundle
+	// directory may already hold images uploaded while writing the post.
+	name, ok := s.name(fullPath)
+	if !ok {
+		return "", frontmatter.Meta{}, fmt.Errorf("无效的文章存储结构: %s", s.Layout)
+	}
+	// Never overwrite another post, whatever the layout: a flat layout
+	// such as {{slug}}.md gives two posts with the same slug the same file
+	if s.exists(fullPath) {
+		return "", frontmatter.Meta{}, fmt.Errorf("目标文件已存在: %s", fullPath)
+	}
+	fsys := s.fsys()
+	if err := fsys.MkdirAll(path.Dir(name), 0755); err != nil {
+		return "", frontmatter.Meta{}, err
+	}
+	if err := fsys.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		return "", frontmatter.Meta{}, err
+	}
+
+	meta := frontmatter.Meta{
+		Title:       p.Title,
+		Date:        currentDate,
+		Slug:        p.Slug,
+		PublishDate: publishDate,
+		ExpiryDate:  expiryDate,
+		Draft:       p.Draft,
+	}
+	return fullPath, meta, nil
+}
+
+// Update updates an existing post in place. Only the fields of p are
+// patched; every other front matter key is written back untouched, in its
+// original order. The post keeps its date directory and publish date, only
+// lastmod is bumped, and the file is renamed only when the slug (or the
+// title it is derived from) changes. When url is set and the edit changes
+// the post's URL, the old URL is kept alive through a Hugo alias.
+func (s Store) Update(id string, p Post, url URLFunc) (Change, error) {
+	publishDate, expiryDate, err := FormatDates(p.PublishDate, p.ExpiryDate)
+	if err != nil {
+		return Change{}, err
+	}
+	p.PublishDate, p.ExpiryDate = publishDate, expiryDate
+
+	oldPath, err := s.Resolve(id)
+	if err != nil {
+		return Change{}, err
+	}
+
+	page, err := s.Page(oldPath)
+	if err != nil {
+		return Change{}, err
+	}
+
+	// The filename the post would have been saved under before this edit
+	oldName := fileName(page.Meta.Title, page.Meta.Slug)
+	safeTitle := fileName(p.Title, p.Slug)
+
+	// Keep the current file (and its name, even if hand-picked) unless the
+	// slug changed; a changed slug moves the post within its date directory,
+	// or renames the bundle directory together with its resources
+	bundle := s.IsBundle(oldPath)
+	fullPath := oldPath
+	if safeTitle != oldName {
+		target := filepath.Join(filepath.Dir(oldPath), fmt.Sprintf("%s.md", safeTitle))
+		if bundle {
+			target = filepath.Join(filepath.Dir(filepath.Dir(oldPath)), safeTitle)
+			fullPath = filepath.Join(target, BundleIndexFile)
+		} else {
+			fullPath = target
+		}
+		if s.exists(target) {
+			return Change{}, fmt.Errorf("目标文件已存在: %s", target)
+		}
+	}
+
+	change := Change{OldPath: oldPath, OldMeta: page.Meta, Path: fullPath}
+	if url != nil {
+		change.OldURL = url(oldPath, page.Meta)
+	}
+
+	if err := page.Patch(formUpdates(page.Meta, p)); err != nil {
+		return Change{}, err
+	}
+	page.Body = p.Content
+
+	if url != nil {
+		change.URL = url(fullPath, page.Meta)
+		if change.OldURL != "" && change.URL != "" && change.OldURL != change.URL {
+			aliases := aliasesAfterMove(page.Meta.Aliases, change.OldURL, change.URL)
+			if err := page.Patch([]frontmatter.Update{frontmatter.Set("aliases", aliases)}); err != nil {
+				return Change{}, err
+			}
+		}
+	}
+	change.Meta = page.Meta
+
+	fsys := s.fsys()
+	oldFile, _ := s.name(oldPath)
+	newFile, _ := s.name(fullPath)
+	if bundle {
+		// Move the whole bundle before writing index.md, so a failed move
+		// leaves the old URL serving the old content
+		if fullPath != oldPath {
+			if err := fsys.Rename(path.Dir(oldFile), path.Dir(newFile)); err != nil {
+				return Change{}, err
+			}
+		}
+		if err := s.WritePage(fullPath, page); err != nil {
+			if fullPath != oldPath {
+				fsys.Rename(path.Dir(newFile), path.Dir(oldFile))
+			}
+			return Change{}, err
+		}
+		return change, nil
+	}
+
+	if err := s.WritePage(fullPath, page); err != nil {
+		return Change{}, err
+	}
+	// Remove the old file once the new one is in place (images are preserved)
+	if fullPath != oldPath {
+		if err := fsys.Remove(oldFile); err != nil {
+			return Change{}, err
+		}
+	}
+	return change, nil
+}
+
+// Delete deletes a post and the images it references. A page bundle is
+// removed with all its resources; for other posts, images under
+// /images/uploads/ are looked up in imageDirectory and other relative
+// references below rootDirectory. Images outside the posts directory are
+// only deleted when the store is on disk. Directories the post leaves empty
+// are removed too. Delete returns the deleted file and its page, which is nil
+// when the front matter could not be parsed.
+func (s Store) Delete(id, imageDirectory, rootDirectory string) (string, *frontmatter.Page, error) {
+	postFilePath, err := s.Resolve(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Read the post content to extract image paths
+	content, err := s.readFile(postFilePath)
+	if err != nil {
+		return "", nil, err
+	}
+	page, err := frontmatter.Parse(content)
+	if err != nil {
+		page = nil
+	}
+
+	// A page bundle owns exactly the files in its directory
+	if s.IsBundle(postFilePath) {
+		bundleDir := filepath.Dir(postFilePath)
+		name, _ := s.name(bundleDir)
+		if err := s.fsys().RemoveAll(name); err != nil {
+			return "", nil, err
+		}
+		s.removeEmptyParents(bundleDir)
+		return postFilePath, page, nil
+	}
+
+	for _, imagePath := range extractImagePaths(string(content), imageDirectory) {
+		absoluteImagePath := imagePath
+		if !filepath.IsAbs(imagePath) {
+			if rootDirectory != "" {
+				absoluteImagePath = filepath.Join(rootDirectory, imagePath)
+			} else {
+				absoluteImagePath = filepath.Join(imageDirectory, imagePath)
+			}
+		}
+
+		// Images that are already gone or cannot be removed are left alone;
+		// the post itself is what has to go
+		s.removeFile(absoluteImagePath)
+	}
+
+	name, _ := s.name(postFilePath)
+	if err := s.fsys().Remove(name); err != nil {
+		return "", nil, err
+	}
+
+	// Remove the date (or year/month) directories the post leaves empty
+	s.removeEmptyParents(postFilePath)
+	return postFilePath, page, nil
+}
+
+// formUpdates returns the front matter changes for the fields of p. Fields
+// whose value did not change are skipped so that their original formatting
+// is kept.
+func formUpdates(meta frontmatter.Meta, p Post) []frontmatter.Update {
+	// 设置默认值（与 Create 一致）
+	weight := p.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+
+	updates := []frontmatter.Update{
+		frontmatter.Set("lastmod", time.Now().Format(TimeFormat)),
+	}
+
+	if meta.Title != p.Title {
+		updates = append(updates, frontmatter.Set("title", p.Title))
+	}
+	if meta.Description != p.Description {
+		updates = append(updates, frontmatter.Set("description", p.Description))
+	}
+	// An empty author keeps the post's authors, however many it lists
+	if p.Author != "" && (len(meta.Author) == 0 || meta.Author[0] != p.Author) {
+		updates = append(updates, frontmatter.Set("author", []string{p.Author}))
+	}
+	if meta.Weight != weight {
+		updates = append(updates, frontmatter.Set("weight", weight))
+	}
+
+	if !equalStrings(meta.Tags, p.Tags) {
+		if len(p.Tags) == 0 {
+			updates = append(updates, frontmatter.Delete("tags"))
+		} else {
+			updates = append(updates, frontmatter.Set("tags", p.Tags))
+		}
+	}
+
+	if !equalStrings(meta.Keywords, p.Keywords) {
+		if len(p.Keywords) == 0 {
+			updates = append(updates, frontmatter.Delete("keywords"))
+		} else {
+			updates = append(updates, frontmatter.Set("keywords", p.Keywords))
+		}
+	}
+
+	if meta.Slug != p.Slug {
+		if p.Slug == "" {
+			updates = append(updates, frontmatter.Delete("slug"))
+		} else {
+			updates = append(updates, frontmatter.Set("slug", p.Slug))
+		}
+	}
+
+	if meta.Draft != p.Draft {
+		if p.Draft {
+			updates = append(updates, frontmatter.Set("draft", true))
+		} else {
+			updates = append(updates, frontmatter.Delete("draft"))
+		}
+	}
+	if !sameDate(meta.PublishDate, p.PublishDate) {
+		// Drop Hugo's alternative key names so only one value remains
+		updates = append(updates, frontmatter.Delete("pubDate"), frontmatter.Delete("published"))
+		if p.PublishDate == "" {
+			updates = append(updates, frontmatter.Delete("publishDate"))
+		} else {
+			updates = append(updates, frontmatter.Set("publishDate", p.PublishDate))
+		}
+	}
+	if !sameDate(meta.ExpiryDate, p.ExpiryDate) {
+		updates = append(updates, frontmatter.Delete("unpublishDate"))
+		if p.ExpiryDate == "" {
+			updates = append(updates, frontmatter.Delete("expiryDate"))
+		} else {
+			updates = append(updates, frontmatter.Set("expiryDate", p.ExpiryDate))
+		}
+	}
+
+	if p.Cover == "" {
+		if meta.Cover.Image != "" {
+			updates = append(updates, frontmatter.Delete("cover.image"), frontmatter.Delete("cover.hiddenInList"))
+		}
+	} else {
+		if meta.Cover.Image != p.Cover {
+			updates = append(updates, frontmatter.Set("cover.image", p.Cover))
+		}
+		if meta.Cover.HiddenInList == nil || *meta.Cover.HiddenInList != p.HiddenInList {
+			updates = append(updates, frontmatter.Set("cover.hiddenInList", p.HiddenInList))
+		}
+	}
+
+	return updates
+}
+
+// sameDate reports whether two front matter dates denote the same instant,
+// so that re-saving a post does not rewrite dates written in another format
+func sameDate(a, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+	ta, okA := frontmatter.ParseDate(a)
+	tb, okB := frontmatter.ParseDate(b)
+	if !okA || !okB {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
+
+// equalStrings reports whether two string slices hold the same values in order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeString escapes special characters in a string for YAML
+func escapeString(s string) string {
+	// Escape backslashes first
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	// Escape double quotes
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return s
+}
+
+// extractImagePaths extracts the image paths of the markdown images in
+// content. Uploads referenced as /images/uploads/<file> are mapped into
+// imageDirectory.
+func extractImagePaths(content, imageDirectory string) []string {
+	var imagePaths []string
+
+	for _, line := range strings.Split(content, "\n") {
+		// Look for markdown image syntax: ![alt](path)
+		if !strings.Contains(line, "![") {
+			continue
+		}
+		start := strings.Index(line, "(")
+		end := strings.Index(line, ")")
+		if start == -1 || end == -1 || end <= start {
+			continue
+		}
+
+		path := strings.Trim(strings.TrimSpace(line[start+1:end]), "\"'")
+		switch {
+		case path == "":
+		case strings.HasPrefix(path, "/images/uploads/"):
+			if imageDirectory != "" {
+				imagePaths = append(imagePaths, filepath.Join(imageDirectory, filepath.Base(path)))
+			}
+		default:
+			imagePaths = append(imagePaths, path)
+		}
+	}
+
+	return imagePaths
+}
@@ -0,0 +1,223 @@
+// Package search is a small in-memory full-text index for posts: an
+// inverted index over titles, descriptions, tags and bodies, ranked with
+// BM25. Chinese, Japanese and Korean text has no spaces between words, so
+// CJK runs are indexed as single characters and overlapping bigrams: any
+// word of two or more characters matches without a dictionary.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Weights of a term occurrence in each field: a match in the title counts
+// five times one in the body
+const (
+	titleWeight       = 5
+	tagsWeight        = 3
+	descriptionWeight = 2
+	bodyWeight        = 1
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Document is what is indexed of a post
+type Document struct {
+	ID          string
+	Title       string
+	Description string
+	Tags        []string // tags, keywords and other labels
+	Body        string
+}
+
+// Hit is a document matching a query
+type Hit struct {
+	ID    string
+	Score float64 // relevance; only comparable within one search
+}
+
+// Index is an inverted index of documents. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*entry
+	postings map[string]map[string]float64 // term -> document ID -> weighted occurrences
+	length   float64                       // total length of the documents
+}
+
+// entry is an indexed document
+type entry struct {
+	doc    Document
+	terms  []string // distinct terms, to remove the postings again
+	length float64  // weighted number of terms
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{docs: make(map[string]*entry), postings: make(map[string]map[string]float64)}
+}
+
+// Len returns the number of documents in the index
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Add indexes doc, replacing the document with the same ID
+func (x *Index) Add(doc Document) {
+	counts := make(map[string]float64)
+	length := 0.0
+	count := func(text string, weight float64) {
+		scan(text, true, func(term string, _, _ int) {
+			counts[term] += weight
+			length += weight
+		})
+	}
+	count(doc.Title, titleWeight)
+	for _, tag := range doc.Tags {
+		count(tag, tagsWeight)
+	}
+	count(doc.Description, descriptionWeight)
+	count(doc.Body, bodyWeight)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(doc.ID)
+
+	e := &entry{doc: doc, terms: make([]string, 0, len(counts)), length: length}
+	for term, n := range counts {
+		postings := x.postings[term]
+		if postings == nil {
+			postings = make(map[string]float64)
+			x.postings[term] = postings
+		}
+		postings[doc.ID] = n
+		e.terms = append(e.terms, term)
+	}
+	x.docs[doc.ID] = e
+	x.length += length
+}
+
+// Remove drops the document id from the index
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(id)
+}
+
+// removeLocked drops a document; the caller must hold x.mu
+func (x *Index) removeLocked(id string) {
+	e, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range e.terms {
+		postings := x.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.docs, id)
+	x.length -= e.length
+}
+
+// Search returns the documents containing every term of query, most
+// relevant first. The last word of the query also matches as a prefix,
+// so results show up while it is being typed.
+func (x *Index) Search(query string) []Hit {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.docs) == 0 {
+		return nil
+	}
+	n := float64(len(x.docs))
+	avgLength := x.length / n
+
+	var scores map[string]float64
+	for i, term := range terms {
+		postings := x.postings[term]
+		if i == len(terms)-1 && isPrefixTerm(term) {
+			postings = x.expand(term)
+		}
+
+		idf := math.Log(1 + (n-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		next := make(map[string]float64)
+		for id, tf := range postings {
+			score, ok := scores[id]
+			if i > 0 && !ok {
+				continue // Every term must match
+			}
+			norm := k1 * (1 - b + b*x.docs[id].length/avgLength)
+			next[id] = score + idf*tf*(k1+1)/(tf+norm)
+		}
+		scores = next
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// isPrefixTerm reports whether a query term may match longer words: it is a
+// word, not CJK, and long enough not to match half the index
+func isPrefixTerm(term string) bool {
+	return len(term) >= 2 && !strings.ContainsFunc(term, isCJK)
+}
+
+// expand merges the postings of term and of the longer words starting with
+// it, which count half; the caller must hold x.mu
+func (x *Index) expand(term string) map[string]float64 {
+	merged := make(map[string]float64)
+	for indexed, postings := range x.postings {
+		if !strings.HasPrefix(indexed, term) {
+			continue
+		}
+		weight := 1.0
+		if indexed != term {
+			weight = 0.5
+		}
+		for id, tf := range postings {
+			merged[id] += weight * tf
+		}
+	}
+	return merged
+}
+
+// matcher returns whether an indexed term matches one of the query's
+// terms, the way Search matches them
+func matcher(terms []string) func(string) bool {
+	set := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		set[term] = true
+	}
+	prefix := ""
+	if len(terms) > 0 && isPrefixTerm(terms[len(terms)-1]) {
+		prefix = terms[len(terms)-1]
+	}
+	return func(term string) bool {
+		return set[term] || prefix != "" && strings.HasPrefix(term, prefix)
+	}
+}
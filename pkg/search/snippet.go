@@ -0,0 +1,174 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SnippetSize is the length of a snippet in characters
+const SnippetSize = 120
+
+// Fragment is a piece of a snippet. Fragments marked Match are occurrences
+// of the query's terms, for the frontend to highlight.
+type Fragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// Snippet returns about SnippetSize characters of the document id around
+// the place matching query best: from its body, or its description when
+// only that matches. A document matching by title or tags alone gets the
+// start of its description or body, without highlights.
+func (x *Index) Snippet(id, query string) []Fragment {
+	x.mu.RLock()
+	e, ok := x.docs[id]
+	x.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	match := matcher(queryTerms(query))
+	for _, text := range []string{e.doc.Body, e.doc.Description} {
+		if fragments := snippet(text, match); fragments != nil {
+			return fragments
+		}
+	}
+
+	text := e.doc.Description
+	if strings.TrimSpace(text) == "" {
+		text = e.doc.Body
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []Fragment{}
+	}
+	end := advance(text, 0, SnippetSize)
+	fragment := Fragment{Text: collapseSpace(text[:end])}
+	if end < len(text) {
+		fragment.Text += "…"
+	}
+	return []Fragment{fragment}
+}
+
+// snippet cuts the window of text holding the most matches and splits it
+// into fragments, or returns nil when nothing in text matches
+func snippet(text string, match func(string) bool) []Fragment {
+	// Byte ranges of the matches, merged where they overlap, as the
+	// overlapping bigrams of a CJK word do
+	var ranges [][2]int
+	for _, t := range tokenize(text, true) {
+		if !match(t.term) {
+			continue
+		}
+		if last := len(ranges) - 1; last >= 0 && t.start <= ranges[last][1] {
+			ranges[last][1] = max(ranges[last][1], t.end)
+			continue
+		}
+		ranges = append(ranges, [2]int{t.start, t.end})
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	// Start a little before the match that has the most others after it
+	bestStart, bestEnd, bestCount := 0, 0, -1
+	for k := 0; k < len(ranges) && k < 100; k++ {
+		start := retreat(text, ranges[k][0], SnippetSize/5)
+		end := advance(text, start, SnippetSize)
+		count := 0
+		for _, r := range ranges[k:] {
+			if r[0] >= end {
+				break
+			}
+			count++
+			end = max(end, r[1]) // Never cut a match in two
+		}
+		if count > bestCount {
+			bestStart, bestEnd, bestCount = start, end, count
+		}
+	}
+
+	var fragments []Fragment
+	add := func(s string, match bool) {
+		if s = collapseSpace(s); s != "" {
+			fragments = append(fragments, Fragment{Text: s, Match: match})
+		}
+	}
+	if bestStart > 0 {
+		add("…", false)
+	}
+	pos := bestStart
+	for _, r := range ranges {
+		if r[1] <= bestStart || r[0] >= bestEnd {
+			continue
+		}
+		start := max(r[0], bestStart)
+		add(text[pos:start], false)
+		add(text[start:r[1]], true)
+		pos = r[1]
+	}
+	add(text[pos:bestEnd], false)
+	if bestEnd < len(text) {
+		add("…", false)
+	}
+	return mergeText(fragments)
+}
+
+// mergeText joins adjacent unmarked fragments and trims the ends
+func mergeText(fragments []Fragment) []Fragment {
+	var merged []Fragment
+	for _, f := range fragments {
+		if last := len(merged) - 1; last >= 0 && !f.Match && !merged[last].Match {
+			merged[last].Text += f.Text
+			continue
+		}
+		merged = append(merged, f)
+	}
+	if len(merged) > 0 {
+		merged[0].Text = strings.TrimLeftFunc(merged[0].Text, unicode.IsSpace)
+		last := len(merged) - 1
+		merged[last].Text = strings.TrimRightFunc(merged[last].Text, unicode.IsSpace)
+	}
+	return merged
+}
+
+// collapseSpace replaces every run of white space, line breaks included,
+// with a single space
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// advance returns the byte offset n characters after pos in text
+func advance(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
+
+// retreat returns the byte offset n characters before pos in text
+func retreat(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is a term found in a text, with its byte offsets in the text
+type token struct {
+	term       string
+	start, end int
+}
+
+// isCJK reports whether r belongs to a script written without spaces
+// between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isWord reports whether r is part of a space-separated word
+func isWord(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// tokenize splits text into lower-cased words and CJK terms. A run of CJK
+// characters yields every character and every pair of adjacent characters
+// when index is set, as documents are indexed; for a query it yields only
+// the pairs, or the character itself when the run is one character long, so
+// that a query matches where all its pairs do.
+func tokenize(text string, index bool) []token {
+	var tokens []token
+	scan(text, index, func(term string, start, end int) {
+		tokens = append(tokens, token{term: term, start: start, end: end})
+	})
+	return tokens
+}
+
+// scan calls emit with each token of text, as tokenize returns them,
+// without collecting them
+func scan(text string, index bool, emit func(term string, start, end int)) {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case isWord(r):
+			start := i
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !isWord(r) {
+					break
+				}
+				i += size
+			}
+			emit(strings.ToLower(text[start:i]), start, i)
+
+		case isCJK(r):
+			// prev is the start of the previous character of the run, -1 at
+			// its first
+			prev, single := -1, true
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !isCJK(r) {
+					break
+				}
+				if prev >= 0 {
+					emit(text[prev:i+size], prev, i+size)
+					single = false
+				}
+				if index {
+					emit(text[i:i+size], i, i+size)
+				}
+				prev = i
+				i += size
+			}
+			if single && !index {
+				emit(text[prev:i], prev, i)
+			}
+
+		default:
+			i += size
+		}
+	}
+}
+
+// queryTerms returns the distinct terms of a query, in order
+func queryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, t := range tokenize(query, false) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			terms = append(terms, t.term)
+		}
+	}
+	return terms
+}
+
+// WordCount counts the words of text the way Hugo does for CJK content:
+// every CJK character is a word, and so is every run of other letters and
+// digits
+func WordCount(text string) int {
+	count := 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			count++
+			inWord = false
+		case isWord(r):
+			if !inWord {
+				count++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
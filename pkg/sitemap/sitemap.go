@@ -0,0 +1,64 @@
+// Package sitemap reads the sitemap.xml files Hugo generates: a urlset, or
+// for multilingual sites a sitemapindex pointing at one urlset per language.
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+)
+
+// URL is one page listed in a sitemap
+type URL struct {
+	Loc     string    `json:"loc"`
+	LastMod time.Time `json:"lastmod"` // zero when the sitemap gives none
+}
+
+// Sitemap is the content of one sitemap file
+type Sitemap struct {
+	URLs     []URL    // pages of a urlset
+	Sitemaps []string // child sitemap locations of a sitemapindex
+}
+
+// document matches both root elements; only one of the lists is filled
+type document struct {
+	XMLName xml.Name `xml:""`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Parse reads a sitemap file
+func Parse(r io.Reader) (*Sitemap, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %v", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("invalid sitemap: unexpected root element <%s>", doc.XMLName.Local)
+	}
+
+	sitemap := &Sitemap{}
+	for _, u := range doc.URLs {
+		loc := strings.TrimSpace(u.Loc)
+		if loc == "" {
+			continue
+		}
+		lastMod, _ := frontmatter.ParseDate(u.LastMod)
+		sitemap.URLs = append(sitemap.URLs, URL{Loc: loc, LastMod: lastMod})
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemap.Sitemaps = append(sitemap.Sitemaps, loc)
+		}
+	}
+	return sitemap, nil
+}
@@ -0,0 +1,105 @@
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"hugo-publisher/pkg/atomicfile"
+)
+
+// Dir is the directory tree rooted at a directory on disk
+type Dir string
+
+// path returns the OS path of name, after checking it for op
+func (d Dir) path(op, name string) (string, error) {
+	if err := checkName(op, name); err != nil {
+		return "", err
+	}
+	return filepath.Join(string(d), filepath.FromSlash(name)), nil
+}
+
+// Open opens the named file for reading
+func (d Dir) Open(name string) (fs.File, error) {
+	p, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Stat returns the FileInfo of the named file
+func (d Dir) Stat(name string) (fs.FileInfo, error) {
+	p, err := d.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(p)
+}
+
+// ReadDir returns the entries of the named directory sorted by name
+func (d Dir) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := d.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(p)
+}
+
+// ReadFile returns the content of the named file
+func (d Dir) ReadFile(name string) ([]byte, error) {
+	p, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+// WriteFile writes the named file through a temporary file and a rename
+func (d Dir) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(p, data, perm)
+}
+
+// MkdirAll creates a directory and any missing parents
+func (d Dir) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := d.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, perm)
+}
+
+// Remove removes a file or an empty directory
+func (d Dir) Remove(name string) error {
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// RemoveAll removes a file or a directory and everything in it
+func (d Dir) RemoveAll(name string) error {
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
+
+// Rename moves a file or directory
+func (d Dir) Rename(oldname, newname string) error {
+	oldPath, err := d.path("rename", oldname)
+	if err != nil {
+		return err
+	}
+	newPath, err := d.path("rename", newname)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
@@ -0,0 +1,183 @@
+package vfs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// Memory is a file system held in memory, for tests and previews. It is
+// safe for concurrent use.
+type Memory struct {
+	mu    sync.RWMutex
+	files fstest.MapFS
+}
+
+// NewMemory returns an empty in-memory file system
+func NewMemory() *Memory {
+	return &Memory{files: fstest.MapFS{}}
+}
+
+// Open opens the named file for reading
+func (m *Memory) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Open(name)
+}
+
+// Stat returns the FileInfo of the named file
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Stat(name)
+}
+
+// ReadDir returns the entries of the named directory sorted by name
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadDir(name)
+}
+
+// ReadFile returns a copy of the content of the named file
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadFile(name)
+}
+
+// isDirLocked reports whether name is a directory; the caller must hold m.mu
+func (m *Memory) isDirLocked(name string) bool {
+	info, err := m.files.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+// WriteFile stores a copy of data as the named file
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := checkName("write", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == "." || m.isDirLocked(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	if !m.isDirLocked(path.Dir(name)) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrNotExist}
+	}
+	m.files[name] = &fstest.MapFile{
+		Data:    append([]byte(nil), data...),
+		Mode:    perm.Perm(),
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// MkdirAll creates a directory and any missing parents
+func (m *Memory) MkdirAll(name string, perm fs.FileMode) error {
+	if err := checkName("mkdir", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := name; dir != "."; dir = path.Dir(dir) {
+		info, err := m.files.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+			}
+			continue
+		}
+		m.files[dir] = &fstest.MapFile{Mode: fs.ModeDir | perm.Perm(), ModTime: time.Now()}
+	}
+	return nil
+}
+
+// Remove removes a file or an empty directory
+func (m *Memory) Remove(name string) error {
+	if err := checkName("remove", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := m.files.Stat(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		if entries, _ := m.files.ReadDir(name); len(entries) > 0 || name == "." {
+			return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+		}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// RemoveAll removes a file or a directory and everything in it
+func (m *Memory) RemoveAll(name string) error {
+	if err := checkName("remove", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.files {
+		if name == "." || key == name || strings.HasPrefix(key, name+"/") {
+			delete(m.files, key)
+		}
+	}
+	return nil
+}
+
+// Rename moves a file or directory. An existing file at newname is
+// replaced; an existing directory is not.
+func (m *Memory) Rename(oldname, newname string) error {
+	if err := checkName("rename", oldname); err != nil {
+		return err
+	}
+	if err := checkName("rename", newname); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := m.files.Stat(oldname)
+	if err != nil || oldname == "." {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	if m.isDirLocked(newname) || (info.IsDir() && strings.HasPrefix(newname, oldname+"/")) {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+	}
+	if !m.isDirLocked(path.Dir(newname)) {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrNotExist}
+	}
+
+	if !info.IsDir() {
+		m.files[newname] = m.files[oldname]
+		delete(m.files, oldname)
+		return nil
+	}
+
+	// Move the directory entry and everything below it
+	moved := make(map[string]*fstest.MapFile)
+	for key, file := range m.files {
+		if key == oldname || strings.HasPrefix(key, oldname+"/") {
+			moved[newname+strings.TrimPrefix(key, oldname)] = file
+			delete(m.files, key)
+		}
+	}
+	for key, file := range moved {
+		m.files[key] = file
+	}
+	if _, ok := moved[newname]; !ok {
+		// oldname only existed implicitly through its files
+		m.files[newname] = &fstest.MapFile{Mode: fs.ModeDir | 0755, ModTime: time.Now()}
+	}
+	return nil
+}
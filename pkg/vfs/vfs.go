@@ -0,0 +1,38 @@
+// Package vfs defines the writable file system posts are stored in. It
+// extends io/fs with the write operations the publisher needs, so post
+// storage runs the same against the disk (Dir), memory (Memory) or any other
+// backend.
+package vfs
+
+import (
+	"io/fs"
+)
+
+// FS is a writable file system. Names are slash separated paths relative to
+// the root of the file system, as in io/fs; "." is the root.
+type FS interface {
+	fs.StatFS
+	fs.ReadDirFS
+	fs.ReadFileFS
+
+	// WriteFile replaces the named file with data. Readers never see a
+	// partially written file. The parent directory must exist.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// MkdirAll creates a directory and any missing parents
+	MkdirAll(name string, perm fs.FileMode) error
+	// Remove removes a file or an empty directory
+	Remove(name string) error
+	// RemoveAll removes a file or a directory and everything in it; a
+	// missing name is not an error
+	RemoveAll(name string) error
+	// Rename moves a file or directory
+	Rename(oldname, newname string) error
+}
+
+// checkName rejects names io/fs does not accept
+func checkName(op, name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// stamp is what polling compares to tell that a file changed
+type stamp struct {
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (s stamp) equal(other stamp) bool {
+	return s.size == other.size && s.modTime.Equal(other.modTime) && s.dir == other.dir
+}
+
+// poll reports changes by scanning the directory every PollInterval and
+// comparing sizes and modification times with the last scan
+func (w *Watcher) poll(ctx context.Context, last map[string]stamp) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := w.scan()
+		pending := make(map[string]bool)
+		for name, s := range current {
+			if old, ok := last[name]; !ok || !old.equal(s) {
+				pending[name] = true
+			}
+		}
+		for name := range last {
+			if _, ok := current[name]; !ok {
+				pending[name] = true
+			}
+		}
+		last = current
+		w.report(pending)
+	}
+}
+
+// scan records the stamp of every file and directory below the watched
+// directory
+func (w *Watcher) scan() map[string]stamp {
+	stamps := make(map[string]stamp)
+	filepath.WalkDir(w.dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || filePath == w.dir {
+			return nil
+		}
+		name, ok := w.name(filePath)
+		if !ok {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			stamps[name] = stamp{dir: true}
+		} else {
+			stamps[name] = stamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return stamps
+}
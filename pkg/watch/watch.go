@@ -0,0 +1,228 @@
+// Package watch reports the files that change below a directory, such as
+// edits made in another editor or by a git pull. It uses the platform's file
+// system notifications, and polls where they are not available: on some
+// network drives, or when the inotify watch limit is reached.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// Debounce is how long a directory must stay quiet before its changes
+	// are reported, so a git pull is reported once rather than per file
+	Debounce = 300 * time.Millisecond
+	// PollInterval is how often the directory is scanned when polling
+	PollInterval = 2 * time.Second
+)
+
+// Watcher watches one directory tree
+type Watcher struct {
+	// Warnf reports why the watcher fell back to polling; nil drops it
+	Warnf func(format string, args ...interface{})
+
+	dir      string
+	onChange func(names []string)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a watcher of dir calling onChange with the slash-separated
+// names, relative to dir, of the files and directories created, written,
+// removed or renamed below it. names is nil when events were lost and
+// anything may have changed. Hidden files and directories are ignored, which
+// skips .git and the temporary files of atomic writes.
+func New(dir string, onChange func(names []string)) *Watcher {
+	return &Watcher{dir: filepath.Clean(dir), onChange: onChange}
+}
+
+// Dir returns the watched directory
+func (w *Watcher) Dir() string {
+	return w.dir
+}
+
+// Start watches in the background until ctx is done or Stop is called
+func (w *Watcher) Start(ctx context.Context) error {
+	info, err := os.Stat(w.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", w.dir)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	// Set up before returning, so no change made after Start is missed
+	notifier, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = w.add(notifier, ".", nil); err != nil {
+			notifier.Close()
+		}
+	}
+	if err != nil {
+		w.warnf("Polling %s for changes: %v", w.dir, err)
+		go w.poll(ctx, w.scan())
+	} else {
+		go w.run(ctx, notifier)
+	}
+	return nil
+}
+
+// Stop stops watching and waits until onChange is no longer called
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (w *Watcher) warnf(format string, args ...interface{}) {
+	if w.Warnf != nil {
+		w.Warnf(format, args...)
+	}
+}
+
+// run reports the changes notifier is told about
+func (w *Watcher) run(ctx context.Context, notifier *fsnotify.Watcher) {
+	defer close(w.done)
+	defer notifier.Close()
+
+	pending := make(map[string]bool)
+	lost := false
+	timer := time.NewTimer(Debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-notifier.Events:
+			if !ok {
+				return
+			}
+			name, ok := w.name(event.Name)
+			if !ok || event.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[name] = true
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Files can be created before the new directory is watched
+					if err := w.add(notifier, name, pending); err != nil {
+						w.warnf("Failed to watch %s: %v", event.Name, err)
+						lost = true
+					}
+				}
+			}
+			timer.Reset(Debounce)
+
+		case err, ok := <-notifier.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				lost = true
+				timer.Reset(Debounce)
+			} else {
+				w.warnf("Watching %s: %v", w.dir, err)
+			}
+
+		case <-timer.C:
+			if lost {
+				w.onChange(nil)
+			} else {
+				w.report(pending)
+			}
+			pending = make(map[string]bool)
+			lost = false
+		}
+	}
+}
+
+// add watches the directory name and the directories below it. With
+// pending set, the files found are recorded as changed.
+func (w *Watcher) add(notifier *fsnotify.Watcher, name string, pending map[string]bool) error {
+	return filepath.WalkDir(w.path(name), func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if filePath == w.path(name) {
+				return err
+			}
+			return nil // Skip directories we can't read
+		}
+		rel, ok := w.name(filePath)
+		switch {
+		case filePath == w.dir:
+		case !ok:
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		case pending != nil:
+			pending[rel] = true
+		}
+		if entry.IsDir() {
+			return notifier.Add(filePath)
+		}
+		return nil
+	})
+}
+
+// report calls onChange with the names in pending, if any
+func (w *Watcher) report(pending map[string]bool) {
+	if len(pending) == 0 {
+		return
+	}
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w.onChange(names)
+}
+
+// path returns the file path of the slash-separated name
+func (w *Watcher) path(name string) string {
+	return filepath.Join(w.dir, filepath.FromSlash(name))
+}
+
+// name returns the slash-separated name of filePath relative to the watched
+// directory, and false for the directory itself, paths outside it and
+// hidden files
+func (w *Watcher) name(filePath string) (string, bool) {
+	rel, err := filepath.Rel(w.dir, filePath)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return rel, true
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/posts"
+)
+
+// redirectsFileName is the file Netlify and Cloudflare Pages read redirects from
+const redirectsFileName = "_redirects"
+
+// SetRedirectsOutput enables writing a Netlify/Cloudflare style _redirects
+// file into staticDirectory whenever UpdatePost changes a post's URL; an
+// empty staticDirectory means the site's static directory. Hugo aliases are
+// always written; this is for hosts that prefer real 301s. The choice is
+// saved in the active profile.
+func (a *App) SetRedirectsOutput(enabled bool, staticDirectory string) error {
+	return a.settings.updateActive(func(profile *SiteProfile) {
+		profile.EmitRedirects = enabled
+		profile.RedirectsDir = staticDirectory
+	})
+}
+
+// writeRedirect records a 301 from oldPath to newPath in staticDirectory's
+// _redirects file. Existing rules for either path are replaced, so renaming a
+// post back and forth never produces a redirect loop.
+func writeRedirect(staticDirectory, oldPath, newPath string) error {
+	redirectsPath := filepath.Join(staticDirectory, redirectsFileName)
+
+	var lines []string
+	data, err := os.ReadFile(redirectsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	kept := make([]string, 0, len(lines)+1)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+			if posts.SameURLPath(fields[0], oldPath) || posts.SameURLPath(fields[0], newPath) {
+				continue
+			}
+			// Point earlier redirects at the new URL instead of chaining them
+			if posts.SameURLPath(fields[1], oldPath) {
+				fields[1] = newPath
+				line = strings.Join(fields, " ")
+			}
+		}
+		kept = append(kept, line)
+	}
+	kept = append(kept, fmt.Sprintf("%s %s 301", oldPath, newPath))
+
+	if err := os.MkdirAll(staticDirectory, 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(redirectsPath, []byte(strings.Join(kept, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/notify"
+	"hugo-publisher/pkg/posts"
+	"hugo-publisher/pkg/sitemap"
+)
+
+// sitemapFileName is the sitemap Hugo writes at the root of the build output
+const sitemapFileName = "sitemap.xml"
+
+// Sources of a bulk resubmission
+const (
+	resubmitSourceSitemap = "sitemap" // the built public/sitemap.xml
+	resubmitSourcePosts   = "posts"   // URLs computed from the posts directory
+)
+
+// ResubmitResult summarizes a bulk resubmission
+type ResubmitResult struct {
+	Source    string   `json:"source"`    // sitemap 或 posts
+	Path      string   `json:"path"`      // 读取的 sitemap 文件或文章目录
+	Total     int      `json:"total"`     // 站点地址总数
+	Queued    int      `json:"queued"`    // 本次提交的新增或更新的地址数
+	Unchanged int      `json:"unchanged"` // 自上次提交以来没有变化的地址数
+	URLs      []string `json:"urls"`      // 本次提交的地址
+}
+
+// resubmitStore remembers, per site profile, the URLs of past bulk
+// resubmissions that got through and their lastmod, so the next one only
+// sends what changed. A URL counts as sent once the outbox reports it
+// submitted to every notifier it was queued for; until then it is pending,
+// and a URL that fails is sent again by the next resubmission.
+type resubmitStore struct {
+	mu        sync.Mutex
+	path      string
+	submitted map[string]map[string]time.Time          // profile -> URL -> lastmod
+	pending   map[string]map[string]*resubmitPending   // profile -> URL -> waiting for the outbox
+	warnf     func(format string, args ...interface{}) // reports failures to save the state
+}
+
+// resubmitPending is a URL queued by a resubmission that not every notifier
+// has submitted yet
+type resubmitPending struct {
+	LastMod   time.Time `json:"lastmod"`
+	Notifiers []string  `json:"notifiers"` // notifiers still to submit the URL
+}
+
+// resubmitFile is the persisted resubmitStore
+type resubmitFile struct {
+	Submitted map[string]map[string]time.Time        `json:"submitted"`
+	Pending   map[string]map[string]*resubmitPending `json:"pending"`
+}
+
+func newResubmitStore(path string) *resubmitStore {
+	return &resubmitStore{
+		path:      path,
+		submitted: make(map[string]map[string]time.Time),
+		pending:   make(map[string]map[string]*resubmitPending),
+	}
+}
+
+// load reads the store file. Files written before pending URLs were
+// tracked hold only the submitted URLs.
+func (s *resubmitStore) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file resubmitFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse resubmission state %s: %v", s.path, err)
+	}
+	if file.Submitted == nil && file.Pending == nil {
+		if err := json.Unmarshal(data, &file.Submitted); err != nil {
+			return fmt.Errorf("failed to parse resubmission state %s: %v", s.path, err)
+		}
+	}
+	if file.Submitted != nil {
+		s.submitted = file.Submitted
+	}
+	if file.Pending != nil {
+		s.pending = file.Pending
+	}
+	return nil
+}
+
+// saveLocked writes the store file; the caller must hold s.mu
+func (s *resubmitStore) saveLocked() error {
+	data, err := json.MarshalIndent(resubmitFile{Submitted: s.submitted, Pending: s.pending}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0600)
+}
+
+// changed splits urls into the ones that are new or have a different
+// lastmod than when they were last submitted for profile, and the rest
+func (s *resubmitStore) changed(profile string, urls []sitemap.URL) ([]sitemap.URL, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.submitted[profile]
+	var changed []sitemap.URL
+	unchanged := 0
+	for _, u := range urls {
+		if lastMod, ok := last[u.Loc]; ok && lastMod.Equal(u.LastMod) {
+			unchanged++
+			continue
+		}
+		changed = append(changed, u)
+	}
+	return changed, unchanged
+}
+
+// queued records the URLs of a resubmission of profile as pending for
+// notifiers, and forgets the URLs that are no longer part of the site
+func (s *resubmitStore) queued(profile string, site, queued []sitemap.URL, notifiers []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make(map[string]bool, len(site))
+	for _, u := range site {
+		current[u.Loc] = true
+	}
+	for loc := range s.submitted[profile] {
+		if !current[loc] {
+			delete(s.submitted[profile], loc)
+		}
+	}
+	for loc := range s.pending[profile] {
+		if !current[loc] {
+			delete(s.pending[profile], loc)
+		}
+	}
+
+	pending := s.pending[profile]
+	if pending == nil {
+		pending = make(map[string]*resubmitPending)
+		s.pending[profile] = pending
+	}
+	for _, u := range queued {
+		pending[u.Loc] = &resubmitPending{LastMod: u.LastMod, Notifiers: append([]string(nil), notifiers...)}
+	}
+	return s.saveLocked()
+}
+
+// finished takes the outcome of outbox submissions: a pending URL becomes
+// submitted once every notifier has submitted it, and is dropped when one
+// of them failed so that the next resubmission sends it again
+func (s *resubmitStore) finished(entries []notify.Submission) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, entry := range entries {
+		pending, ok := s.pending[entry.Profile][entry.URL]
+		if !ok {
+			continue
+		}
+		changed = true
+		if entry.Status != notify.SubmissionSubmitted {
+			delete(s.pending[entry.Profile], entry.URL)
+			continue
+		}
+		pending.Notifiers = slices.DeleteFunc(pending.Notifiers, func(name string) bool { return name == entry.Notifier })
+		if len(pending.Notifiers) > 0 {
+			continue
+		}
+		delete(s.pending[entry.Profile], entry.URL)
+		if s.submitted[entry.Profile] == nil {
+			s.submitted[entry.Profile] = make(map[string]time.Time)
+		}
+		s.submitted[entry.Profile][entry.URL] = pending.LastMod
+	}
+	if !changed {
+		return
+	}
+	if err := s.saveLocked(); err != nil {
+		s.warnf("Failed to save resubmission state: %v", err)
+	}
+}
+
+// sitemapURLs reads the sitemap in publicDir, following a sitemap index to
+// the per-language sitemaps. It returns os.ErrNotExist when the site has not
+// been built.
+func sitemapURLs(publicDir, baseURL string) ([]sitemap.URL, error) {
+	basePath := "/"
+	if u, err := url.Parse(baseURL); err == nil && u.Path != "" {
+		basePath = u.Path
+	}
+
+	var urls []sitemap.URL
+	seen := make(map[string]bool)
+	var read func(file string, depth int) error
+	read = func(file string, depth int) error {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		parsed, err := sitemap.Parse(f)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		for _, u := range parsed.URLs {
+			if !seen[u.Loc] {
+				seen[u.Loc] = true
+				urls = append(urls, u)
+			}
+		}
+		if depth > 0 {
+			return nil // Hugo never nests sitemap indexes
+		}
+		for _, loc := range parsed.Sitemaps {
+			u, err := url.Parse(loc)
+			if err != nil {
+				continue
+			}
+			rel := strings.TrimPrefix(path.Clean(u.Path), path.Clean(basePath))
+			child := filepath.Join(publicDir, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+			if err := read(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := read(filepath.Join(publicDir, sitemapFileName), 0); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// postSiteURLs computes the URLs of the public posts in directory, with
+// lastmod (or the publish date) as their lastmod
+func (a *App) postSiteURLs(profile SiteProfile, directory string) ([]sitemap.URL, error) {
+	store := a.store(directory)
+	files, err := store.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var urls []sitemap.URL
+	for _, filePath := range files {
+		page, err := store.Page(filePath)
+		if err != nil || !posts.IsPublic(page.Meta, now) {
+			continue
+		}
+
+		urlPath, err := a.postPath(profile, directory, filePath, page.Meta)
+		if err != nil {
+			continue
+		}
+		loc := profile.URL(urlPath)
+		if loc == "" {
+			continue
+		}
+		lastMod, ok := frontmatter.ParseDate(page.Meta.LastMod)
+		if !ok {
+			lastMod, ok = frontmatter.ParseDate(page.Meta.PublishDate)
+		}
+		if !ok {
+			lastMod, _ = frontmatter.ParseDate(page.Meta.Date)
+		}
+		urls = append(urls, sitemap.URL{Loc: loc, LastMod: lastMod})
+	}
+	return urls, nil
+}
+
+// ResubmitSite submits the URLs of the site at rootDirectory that are new or
+// changed since the last resubmission to the active profile's notifiers.
+// URLs come from the built sitemap.xml, or when the site has not been built
+// from the posts in directory. force submits every URL, e.g. after a theme
+// change.
+func (a *App) ResubmitSite(rootDirectory, directory string, force bool) (ResubmitResult, error) {
+	profile := a.site()
+	if profile.BaseURL == "" {
+		return ResubmitResult{}, errors.New("未设置站点地址")
+	}
+	if err := requireNotifiers(profile); err != nil {
+		return ResubmitResult{}, err
+	}
+
+	var result ResubmitResult
+	var urls []sitemap.URL
+	err := os.ErrNotExist
+	_, publicDir := a.siteDirs(profile, rootDirectory)
+	if publicDir != "" {
+		urls, err = sitemapURLs(publicDir, profile.BaseURL)
+	}
+	switch {
+	case err == nil:
+		result.Source = resubmitSourceSitemap
+		result.Path = filepath.Join(publicDir, sitemapFileName)
+	case errors.Is(err, os.ErrNotExist) && directory != "":
+		result.Source = resubmitSourcePosts
+		result.Path = directory
+		if urls, err = a.postSiteURLs(profile, directory); err != nil {
+			return ResubmitResult{}, err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		return ResubmitResult{}, errors.New("找不到站点的 sitemap.xml，请先构建站点或指定文章目录")
+	default:
+		return ResubmitResult{}, err
+	}
+	result.Total = len(urls)
+
+	changed := urls
+	if !force {
+		changed, result.Unchanged = a.resubmits.changed(profile.Name, urls)
+	}
+	result.URLs = make([]string, len(changed))
+	for i, u := range changed {
+		result.URLs[i] = u.Loc
+	}
+	result.Queued = len(result.URLs)
+	if result.Queued == 0 {
+		return result, nil
+	}
+
+	// Only what the outbox reports as submitted counts as sent, see
+	// resubmitStore.finished
+	notifiers, err := a.queueURLs(profile, false, result.URLs...)
+	if len(notifiers) > 0 {
+		if err := a.resubmits.queued(profile.Name, urls, changed, notifiers); err != nil {
+			a.warnf("Failed to save resubmission state: %v", err)
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/posts"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Schedule entry states
+const (
+	schedulePending   = "pending"   // waiting for its publish time
+	schedulePublished = "published" // published, kept only to report a missed schedule
+	scheduleFailed    = "failed"    // the post vanished or the build step failed
+)
+
+// missedGrace is how late an entry may run before it counts as missed
+const missedGrace = time.Minute
+
+// buildTimeout bounds the configured build/deploy command
+const buildTimeout = 10 * time.Minute
+
+// ScheduledPost is a pending publication in the scheduling queue
+type ScheduledPost struct {
+	ID          string    `json:"id"`          // 文章ID
+	Directory   string    `json:"directory"`   // 文章目录
+	Title       string    `json:"title"`       // 文章标题
+	URL         string    `json:"url"`         // 发布后提交给搜索引擎的地址
+	Profile     string    `json:"profile"`     // 站点配置名称
+	Root        string    `json:"root"`        // 站点根目录，构建命令在此目录执行
+	PublishAt   time.Time `json:"publishAt"`   // 计划发布时间
+	Status      string    `json:"status"`      // pending / published / failed
+	Missed      bool      `json:"missed"`      // 应用未运行，错过了计划时间
+	PublishedAt time.Time `json:"publishedAt"` // 实际发布时间
+	Error       string    `json:"error"`       // 失败原因
+}
+
+// scheduler publishes queued posts at their publish time. The queue is
+// persisted as JSON so it survives restarts.
+type scheduler struct {
+	mu      sync.Mutex
+	path    string
+	entries []ScheduledPost
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	warnf   func(format string, args ...interface{}) // reports failures of scheduled publications
+}
+
+func newScheduler(path string) *scheduler {
+	return &scheduler{path: path, wake: make(chan struct{}, 1)}
+}
+
+// load reads the queue file and flags pending entries whose time passed
+// while the app was not running. It returns the missed entries.
+func (s *scheduler) load(now time.Time) ([]ScheduledPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %v", s.path, err)
+	}
+
+	var missed []ScheduledPost
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if entry.Status == schedulePending && now.Sub(entry.PublishAt) > missedGrace {
+			entry.Missed = true
+			missed = append(missed, *entry)
+		}
+	}
+	if len(missed) > 0 {
+		return missed, s.saveLocked()
+	}
+	return nil, nil
+}
+
+// saveLocked writes the queue file; the caller must hold s.mu
+func (s *scheduler) saveLocked() error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0600)
+}
+
+// put queues entry, replacing any pending entry for the same post
+func (s *scheduler) put(entry ScheduledPost) error {
+	s.mu.Lock()
+	s.removeLocked(entry.Directory, entry.ID)
+	entry.Status = schedulePending
+	s.entries = append(s.entries, entry)
+	err := s.saveLocked()
+	s.mu.Unlock()
+
+	s.notify()
+	return err
+}
+
+// remove drops the pending entry of a post, if any
+func (s *scheduler) remove(directory, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.removeLocked(directory, id) {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+func (s *scheduler) removeLocked(directory, id string) bool {
+	for i, entry := range s.entries {
+		if entry.Status == schedulePending && entry.ID == id && sameDirectory(entry.Directory, directory) {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// sameDirectory reports whether two posts directories are the same, however
+// they were written (trailing separator, "..", doubled separators)
+func sameDirectory(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// list returns a copy of the queue ordered by publish time
+func (s *scheduler) list() []ScheduledPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]ScheduledPost, len(s.entries))
+	copy(entries, s.entries)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].PublishAt.Before(entries[j].PublishAt) })
+	return entries
+}
+
+// due returns the pending entries whose time has come, and when the next
+// one is due (zero when the queue has none left)
+func (s *scheduler) due(now time.Time) ([]ScheduledPost, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []ScheduledPost
+	var next time.Time
+	for _, entry := range s.entries {
+		if entry.Status != schedulePending {
+			continue
+		}
+		if !entry.PublishAt.After(now) {
+			due = append(due, entry)
+		} else if next.IsZero() || entry.PublishAt.Before(next) {
+			next = entry.PublishAt
+		}
+	}
+	return due, next
+}
+
+// finish records the outcome of a queue entry. Published entries are
+// dropped unless they were missed and still have to be reported.
+func (s *scheduler) finish(entry ScheduledPost, publishErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.entries {
+		// An entry rescheduled or replaced meanwhile is left alone
+		if existing.Status != schedulePending || existing.ID != entry.ID || !sameDirectory(existing.Directory, entry.Directory) ||
+			!existing.PublishAt.Equal(entry.PublishAt) {
+			continue
+		}
+		if publishErr == nil && !existing.Missed {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+		} else {
+			existing.PublishedAt = time.Now()
+			existing.Status = schedulePublished
+			if publishErr != nil {
+				existing.Status = scheduleFailed
+				existing.Error = publishErr.Error()
+			}
+			s.entries[i] = existing
+		}
+		return s.saveLocked()
+	}
+	return nil
+}
+
+// reschedule moves a pending entry to a new time, e.g. when its post's
+// publishDate was edited outside the app
+func (s *scheduler) reschedule(entry ScheduledPost, publishAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.entries {
+		if existing.Status == schedulePending && existing.ID == entry.ID && sameDirectory(existing.Directory, entry.Directory) {
+			s.entries[i].PublishAt = publishAt
+			return s.saveLocked()
+		}
+	}
+	return nil
+}
+
+// dismiss drops every finished entry (missed or failed) from the queue
+func (s *scheduler) dismiss() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if entry.Status == schedulePending {
+			entry.Missed = false
+			kept = append(kept, entry)
+		}
+	}
+	s.entries = kept
+	return s.saveLocked()
+}
+
+// notify wakes the scheduler loop after the queue changed
+func (s *scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run publishes due entries until ctx is cancelled
+func (s *scheduler) run(ctx context.Context, publish func(ScheduledPost) error) {
+	for {
+		due, next := s.due(time.Now())
+		for _, entry := range due {
+			err := publish(entry)
+			if err != nil {
+				s.warnf("Scheduled publication of %s failed: %v", entry.ID, err)
+			}
+			if err := s.finish(entry, err); err != nil {
+				s.warnf("Failed to save schedule: %v", err)
+			}
+		}
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// start launches the scheduler loop
+func (s *scheduler) start(ctx context.Context, publish func(ScheduledPost) error) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx, publish)
+}
+
+// stop ends the scheduler loop
+func (s *scheduler) stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// startScheduler loads the publishing queue, reports schedules missed while
+// the app was closed and starts publishing. Missed posts are published right
+// away.
+func (a *App) startScheduler(ctx context.Context) {
+	missed, err := a.schedule.load(time.Now())
+	if err != nil {
+		a.warnf("Failed to load schedule: %v", err)
+	}
+	if len(missed) > 0 {
+		wailsruntime.EventsEmit(ctx, "schedule:missed", missed)
+	}
+	a.schedule.start(ctx, a.publishScheduled)
+}
+
+// schedulePost queues a post for publication when it is set to go live in
+// the future, and drops any earlier entry of it otherwise
+func (a *App) schedulePost(directory, filePath string, meta frontmatter.Meta, profile SiteProfile, postURL string) {
+	id := a.store(directory).ID(filePath)
+	publishAt, ok := frontmatter.ParseDate(meta.PublishDate)
+	if !ok || !publishAt.After(time.Now()) {
+		if err := a.schedule.remove(directory, id); err != nil {
+			a.warnf("Failed to save schedule: %v", err)
+		}
+		return
+	}
+
+	root := directory
+	if cfg := a.hugoConfig(); cfg != nil {
+		root = cfg.Root
+	}
+	entry := ScheduledPost{
+		ID:        id,
+		Directory: directory,
+		Title:     meta.Title,
+		URL:       postURL,
+		Profile:   profile.Name,
+		Root:      root,
+		PublishAt: publishAt,
+	}
+	if err := a.schedule.put(entry); err != nil {
+		a.warnf("Failed to save schedule: %v", err)
+	}
+}
+
+// unschedulePost drops the queue entry of a post that moved or was deleted
+func (a *App) unschedulePost(directory, filePath string) {
+	if err := a.schedule.remove(directory, a.store(directory).ID(filePath)); err != nil {
+		a.warnf("Failed to save schedule: %v", err)
+	}
+}
+
+// publishScheduled makes a queued post live: it flips draft to false, runs
+// the profile's build/deploy command and only then notifies search engines
+func (a *App) publishScheduled(entry ScheduledPost) error {
+	store := a.store(entry.Directory)
+	filePath := filepath.Join(entry.Directory, filepath.FromSlash(entry.ID))
+	page, err := store.Page(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("文章不存在: %s", entry.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The publish date may have been moved by hand since the post was queued
+	if publishAt, ok := frontmatter.ParseDate(page.Meta.PublishDate); ok && publishAt.After(time.Now()) {
+		return a.schedule.reschedule(entry, publishAt)
+	}
+
+	if page.Meta.Draft {
+		if err := page.Patch([]frontmatter.Update{frontmatter.Set("draft", false)}); err != nil {
+			return err
+		}
+		if err := store.WritePage(filePath, page); err != nil {
+			return err
+		}
+	}
+
+	profile := a.profileNamed(entry.Profile)
+	if profile.BuildCommand != "" {
+		if err := runBuildCommand(profile.BuildCommand, entry.Root); err != nil {
+			return err
+		}
+	}
+
+	if entry.URL != "" && posts.IsPublic(page.Meta, time.Now()) {
+		if _, err := a.queueURLs(profile, profile.BuildCommand != "", entry.URL); err != nil {
+			a.notifyFailed(err)
+		}
+	}
+
+	if a.ctx != nil {
+		wailsruntime.EventsEmit(a.ctx, "schedule:published", entry)
+	}
+	return nil
+}
+
+// profileNamed returns the named profile, falling back to the active one
+func (a *App) profileNamed(name string) SiteProfile {
+	for _, profile := range a.settings.profiles() {
+		if profile.Name == name {
+			return profile
+		}
+	}
+	return a.site()
+}
+
+// runBuildCommand runs a build/deploy command through the system shell in dir
+func runBuildCommand(command, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), buildTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("构建命令执行失败: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// ListScheduledPosts returns the publishing queue: pending publications and
+// finished ones that still have to be reported
+func (a *App) ListScheduledPosts() []ScheduledPost {
+	return a.schedule.list()
+}
+
+// GetMissedSchedules returns the publications that were due while the app
+// was not running, and failed ones
+func (a *App) GetMissedSchedules() []ScheduledPost {
+	var missed []ScheduledPost
+	for _, entry := range a.schedule.list() {
+		if entry.Missed || entry.Status == scheduleFailed {
+			missed = append(missed, entry)
+		}
+	}
+	return missed
+}
+
+// DismissMissedSchedules clears the reported missed and failed publications
+func (a *App) DismissMissedSchedules() error {
+	return a.schedule.dismiss()
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"hugo-publisher/pkg/atomicfile"
+	"hugo-publisher/pkg/posts"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SiteProfile holds the settings of one Hugo site the publisher writes to
+type SiteProfile struct {
+	Name             string           `json:"name"`
+	BaseURL          string           `json:"baseURL"`          // 站点地址，如 https://example.com/
+	ContentDir       string           `json:"contentDir"`       // 文章保存目录
+	StaticDir        string           `json:"staticDir"`        // Hugo static 目录
+	ImageDir         string           `json:"imageDir"`         // 图片上传目录
+	DefaultAuthor    string           `json:"defaultAuthor"`    // 默认作者
+	IndexNowKey      string           `json:"indexNowKey"`      // IndexNow key
+	PermalinkPattern string           `json:"permalinkPattern"` // 文章链接格式，如 /:year-:month-:day/:slug/
+	PostLayout       string           `json:"postLayout"`       // 文章存储结构：date（默认）、bundle 或路径模板，如 {{year}}/{{month}}/{{slug}}.md
+	BuildCommand     string           `json:"buildCommand"`     // 定时发布时执行的构建/部署命令，如 hugo --minify
+	Notifiers        []NotifierConfig `json:"notifiers"`        // 搜索引擎通知渠道；留空且设置了 IndexNow key 时使用 IndexNow
+	EmitRedirects    bool             `json:"emitRedirects"`    // 文章链接变化时同时写入 _redirects（Netlify/Cloudflare Pages）
+	RedirectsDir     string           `json:"redirectsDir"`     // _redirects 写入目录，留空时使用 Hugo static 目录
+}
+
+// NotifierConfig configures one channel search engines are notified through
+type NotifierConfig struct {
+	Name     string `json:"name"`     // 名称，留空时使用类型；提交记录按名称区分渠道
+	Type     string `json:"type"`     // indexnow / baidu / webhook
+	Endpoint string `json:"endpoint"` // indexnow：bing、yandex、seznam 或接口地址；webhook：回调地址；baidu：留空
+	Token    string `json:"token"`    // baidu：推送 token；webhook：签名密钥；indexnow：不使用（见 indexNowKey）
+	Disabled bool   `json:"disabled"` // 暂停此渠道
+}
+
+// Host returns the host name of the profile's base URL
+func (p SiteProfile) Host() string {
+	u, err := url.Parse(p.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// URL returns the absolute URL of a site-relative path, or "" when the
+// profile has no base URL
+func (p SiteProfile) URL(path string) string {
+	if p.BaseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(p.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Settings is the persisted settings file
+type Settings struct {
+	ActiveProfile string        `json:"activeProfile"`
+	Profiles      []SiteProfile `json:"profiles"`
+}
+
+// settingsStore loads and saves Settings as JSON in the user config directory
+type settingsStore struct {
+	mu       sync.Mutex
+	path     string
+	settings Settings
+}
+
+// defaultSettingsPath returns <user config dir>/hugo-publisher/settings.json
+func defaultSettingsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "hugo-publisher", "settings.json"), nil
+}
+
+func newSettingsStore(path string) *settingsStore {
+	return &settingsStore{path: path}
+}
+
+// load reads the settings file; a missing file means no profiles yet
+func (s *settingsStore) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.settings = Settings{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse settings %s: %v", s.path, err)
+	}
+	s.settings = settings
+	return nil
+}
+
+// saveLocked writes the settings file; the caller must hold s.mu
+func (s *settingsStore) saveLocked() error {
+	data, err := json.MarshalIndent(s.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0600)
+}
+
+func (s *settingsStore) profiles() []SiteProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profiles := make([]SiteProfile, len(s.settings.Profiles))
+	copy(profiles, s.settings.Profiles)
+	return profiles
+}
+
+// active returns the active profile, or a zero profile when none is set
+func (s *settingsStore) active() SiteProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, profile := range s.settings.Profiles {
+		if profile.Name == s.settings.ActiveProfile {
+			return profile
+		}
+	}
+	return SiteProfile{}
+}
+
+// put creates or replaces the profile with the same name. The first profile
+// ever created becomes the active one.
+func (s *settingsStore) put(profile SiteProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replaced := false
+	for i, existing := range s.settings.Profiles {
+		if existing.Name == profile.Name {
+			s.settings.Profiles[i] = profile
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.settings.Profiles = append(s.settings.Profiles, profile)
+	}
+	if s.settings.ActiveProfile == "" {
+		s.settings.ActiveProfile = profile.Name
+	}
+	return s.saveLocked()
+}
+
+// updateActive applies update to the active profile and saves it
+func (s *settingsStore) updateActive(update func(profile *SiteProfile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.settings.Profiles {
+		if s.settings.Profiles[i].Name == s.settings.ActiveProfile {
+			update(&s.settings.Profiles[i])
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("请先创建站点配置")
+}
+
+func (s *settingsStore) activate(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, profile := range s.settings.Profiles {
+		if profile.Name == name {
+			s.settings.ActiveProfile = name
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("站点配置不存在: %s", name)
+}
+
+func (s *settingsStore) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, profile := range s.settings.Profiles {
+		if profile.Name == name {
+			s.settings.Profiles = append(s.settings.Profiles[:i], s.settings.Profiles[i+1:]...)
+			if s.settings.ActiveProfile == name {
+				s.settings.ActiveProfile = ""
+				if len(s.settings.Profiles) > 0 {
+					s.settings.ActiveProfile = s.settings.Profiles[0].Name
+				}
+			}
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("站点配置不存在: %s", name)
+}
+
+// ListProfiles returns all saved site profiles
+func (a *App) ListProfiles() []SiteProfile {
+	return a.settings.profiles()
+}
+
+// GetActiveProfile returns the profile currently in use. The returned
+// profile is empty when no profile has been created yet.
+func (a *App) GetActiveProfile() SiteProfile {
+	return a.settings.active()
+}
+
+// SaveProfile creates a site profile, or replaces the one with the same name
+func (a *App) SaveProfile(profile SiteProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("站点配置名称不能为空")
+	}
+	if err := posts.ValidateLayout(profile.PostLayout); err != nil {
+		return err
+	}
+	if profile.BaseURL != "" {
+		u, err := url.Parse(profile.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("无效的站点地址: %s", profile.BaseURL)
+		}
+	}
+	if profile.IndexNowKey != "" && !indexNowKeyPattern.MatchString(profile.IndexNowKey) {
+		return fmt.Errorf("无效的 IndexNow key: 只能包含字母、数字和连字符，长度 8-128")
+	}
+	if err := validateNotifiers(profile.Notifiers); err != nil {
+		return err
+	}
+	return a.settings.put(profile)
+}
+
+// SwitchProfile makes the named profile the active one. The GUI is told
+// with a profile:activated event, so it moves to the profile's directories.
+func (a *App) SwitchProfile(name string) error {
+	if err := a.settings.activate(name); err != nil {
+		return err
+	}
+	if a.ctx != nil {
+		wailsruntime.EventsEmit(a.ctx, "profile:activated", a.settings.active())
+	}
+	return nil
+}
+
+// DeleteProfile removes the named profile. If it was active, the first
+// remaining profile becomes active.
+func (a *App) DeleteProfile(name string) error {
+	return a.settings.remove(name)
+}
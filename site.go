@@ -0,0 +1,113 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"hugo-publisher/pkg/frontmatter"
+	"hugo-publisher/pkg/hugoconfig"
+	"hugo-publisher/pkg/permalink"
+)
+
+// LoadSiteConfig reads the Hugo configuration (hugo.toml, config.yaml,
+// config/_default/ ...) of the site rooted at rootDirectory. The result is
+// kept so that post URLs follow the site's real baseURL and permalinks.
+func (a *App) LoadSiteConfig(rootDirectory string) (hugoconfig.Config, error) {
+	cfg, err := hugoconfig.Load(rootDirectory)
+	if err != nil {
+		a.siteMu.Lock()
+		a.siteConfig = nil
+		a.siteMu.Unlock()
+		return hugoconfig.Config{}, err
+	}
+
+	a.siteMu.Lock()
+	a.siteConfig = cfg
+	a.siteMu.Unlock()
+	return *cfg, nil
+}
+
+// hugoConfig returns the loaded Hugo configuration, or nil
+func (a *App) hugoConfig() *hugoconfig.Config {
+	a.siteMu.Lock()
+	defer a.siteMu.Unlock()
+	return a.siteConfig
+}
+
+// site returns the active profile with the values of the loaded Hugo
+// configuration applied on top, so settings the site already declares do
+// not have to be duplicated in the profile
+func (a *App) site() SiteProfile {
+	profile := a.settings.active()
+
+	cfg := a.hugoConfig()
+	if cfg == nil {
+		return profile
+	}
+	if cfg.BaseURL != "" {
+		profile.BaseURL = cfg.BaseURL
+	}
+	profile.StaticDir = cfg.StaticPath()
+	return profile
+}
+
+// ResolvePostURL returns the public URL of the post with the given ID,
+// following the site's permalink configuration
+func (a *App) ResolvePostURL(id, directory string) (string, error) {
+	store := a.store(directory)
+	postFilePath, err := store.Resolve(id)
+	if err != nil {
+		return "", err
+	}
+	page, err := store.Page(postFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	profile := a.site()
+	path, err := a.postPath(profile, directory, postFilePath, page.Meta)
+	if err != nil {
+		return "", err
+	}
+	if profile.BaseURL == "" {
+		return path, nil
+	}
+	return profile.URL(path), nil
+}
+
+// postPath returns the site-relative URL of the post at filePath. A post
+// without a date has none when the permalink pattern uses the date.
+func (a *App) postPath(profile SiteProfile, directory, filePath string, meta frontmatter.Meta) (string, error) {
+	resolver := permalink.Resolver{Default: profile.PermalinkPattern}
+
+	// Content paths are relative to the Hugo content dir when the posts
+	// directory lives inside it, otherwise to the posts directory itself
+	base := directory
+	if cfg := a.hugoConfig(); cfg != nil {
+		resolver.Patterns = cfg.Permalinks
+		if rel, err := filepath.Rel(cfg.ContentPath(), directory); err == nil && filepath.IsLocal(rel) {
+			base = cfg.ContentPath()
+		}
+	}
+
+	rel, err := filepath.Rel(base, filePath)
+	if err != nil {
+		rel = filepath.Base(filePath)
+	}
+	// Hugo's default front matter config takes .Date from the first of these
+	var date time.Time
+	for _, value := range []string{meta.Date, meta.PublishDate, meta.LastMod} {
+		if parsed, ok := frontmatter.ParseDate(value); ok {
+			date = parsed
+			break
+		}
+	}
+
+	return resolver.Path(permalink.Page{
+		Path:  filepath.ToSlash(rel),
+		Title: meta.Title,
+		Slug:  meta.Slug,
+		URL:   meta.URL,
+		Date:  date,
+	})
+}
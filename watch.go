@@ -0,0 +1,125 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+
+	"hugo-publisher/pkg/watch"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// PostsChangedEvent is sent with posts:changed when post files change on disk
+type PostsChangedEvent struct {
+	Directory string   `json:"directory"` // 文章目录
+	IDs       []string `json:"ids"`       // 变化的文章 ID，为 null 时可能所有文章都有变化
+}
+
+// ImagesChangedEvent is sent with images:changed when images change on disk
+type ImagesChangedEvent struct {
+	Directory string   `json:"directory"` // 图片目录
+	Files     []string `json:"files"`     // 变化的文件，相对图片目录，为 null 时可能所有文件都有变化
+}
+
+// directoryWatch holds the watchers of the posts and image directories the
+// frontend currently shows
+type directoryWatch struct {
+	mu     sync.Mutex
+	posts  *watch.Watcher
+	images *watch.Watcher
+}
+
+// WatchDirectories watches the posts directory and the image directory for
+// changes made outside the app, e.g. in an editor or by git pull, and emits
+// posts:changed and images:changed. The post index is refreshed before
+// posts:changed is sent, so listing right away is cheap. Calling it again
+// replaces the watched directories; an empty directory stops watching it.
+func (a *App) WatchDirectories(directory, imageDirectory string) error {
+	a.watch.mu.Lock()
+	defer a.watch.mu.Unlock()
+
+	var err error
+	a.watch.posts, err = a.rewatch(a.watch.posts, directory, a.postsChanged)
+	if err != nil {
+		return err
+	}
+	a.watch.images, err = a.rewatch(a.watch.images, imageDirectory, a.imagesChanged)
+	return err
+}
+
+// rewatch replaces watcher with one of directory, unless it already
+// watches directory
+func (a *App) rewatch(watcher *watch.Watcher, directory string, onChange func(directory string, names []string)) (*watch.Watcher, error) {
+	if watcher != nil && directory != "" && watcher.Dir() == filepath.Clean(directory) {
+		return watcher, nil
+	}
+	if watcher != nil {
+		watcher.Stop()
+	}
+	if directory == "" || a.ctx == nil {
+		return nil, nil
+	}
+
+	watcher = watch.New(directory, func(names []string) {
+		onChange(directory, names)
+	})
+	watcher.Warnf = a.warnf
+	if err := watcher.Start(a.ctx); err != nil {
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// stopWatching stops the directory watchers
+func (a *App) stopWatching() {
+	a.watch.mu.Lock()
+	defer a.watch.mu.Unlock()
+
+	for _, watcher := range []*watch.Watcher{a.watch.posts, a.watch.images} {
+		if watcher != nil {
+			watcher.Stop()
+		}
+	}
+	a.watch.posts, a.watch.images = nil, nil
+}
+
+// postsChanged refreshes the post index and tells the frontend which posts
+// changed below directory
+func (a *App) postsChanged(directory string, names []string) {
+	store := a.store(directory)
+	refreshed, err := store.Refresh()
+	if err != nil {
+		a.warnf("Failed to refresh post index of %s: %v", directory, err)
+	}
+
+	// The index catches posts that went away with their directory; the
+	// names catch posts already re-read by a listing since they changed
+	event := PostsChangedEvent{Directory: directory}
+	if names != nil {
+		event.IDs = []string{}
+		seen := make(map[string]bool)
+		add := func(id string) {
+			if !seen[id] {
+				seen[id] = true
+				event.IDs = append(event.IDs, id)
+			}
+		}
+		for _, id := range refreshed {
+			add(id)
+		}
+		for _, name := range names {
+			if id, ok := store.Owner(name); ok {
+				add(id)
+			}
+		}
+		if len(event.IDs) == 0 {
+			return
+		}
+	}
+	wailsruntime.EventsEmit(a.ctx, "posts:changed", event)
+}
+
+// imagesChanged tells the frontend which files changed below directory
+func (a *App) imagesChanged(directory string, names []string) {
+	wailsruntime.EventsEmit(a.ctx, "images:changed", ImagesChangedEvent{Directory: directory, Files: names})
+}